@@ -1,32 +1,62 @@
 package main
 
 import (
+	"context"
 	"log"
-	"os"
+	"net/http"
 	"spotlight-backend-go/internal/api"
+	"spotlight-backend-go/internal/config"
 	"spotlight-backend-go/internal/database"
+	"spotlight-backend-go/internal/feed"
+	"spotlight-backend-go/internal/lifecycle"
 	"spotlight-backend-go/internal/middleware"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/notifications"
+	"spotlight-backend-go/internal/payments"
+	"spotlight-backend-go/internal/realtime"
+	"spotlight-backend-go/internal/sms"
+	"spotlight-backend-go/internal/storage"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	if os.Getenv("GIN_MODE") == "release" {
-		gin.SetMode(gin.ReleaseMode)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	// Run database migrations
-	if err := database.RunMigrations(); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	if cfg.GinMode != "" {
+		gin.SetMode(cfg.GinMode)
+	}
+
+	// Run database migrations, unless they are applied separately with
+	// cmd/migrate
+	if cfg.MigrateOnStartup {
+		if err := database.RunMigrations(cfg.Database); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+	} else {
+		database.WarnPendingMigrations(cfg.Database)
 	}
 
 	// Initialize database
-	db := database.InitDB()
+	db := database.InitDB(cfg.Database)
 
-	// Get port from env
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Compare the schema with the models. Drift is logged, or fatal when
+	// SCHEMA_CHECK is strict
+	if cfg.SchemaCheck != config.SchemaCheckOff {
+		problems, err := database.CheckSchema(db)
+		if err != nil {
+			log.Printf("Could not check the database schema: %v", err)
+		}
+		for _, problem := range problems {
+			log.Printf("Schema drift: %s", problem)
+		}
+		if len(problems) > 0 && cfg.SchemaCheck == config.SchemaCheckStrict {
+			log.Fatalf("Database schema does not match the models (%d problems)", len(problems))
+		}
 	}
 
 	router := gin.Default()
@@ -40,7 +70,7 @@ func main() {
 	})
 
 	// Security & Rate limiting — skip in local dev
-	if os.Getenv("LOCAL_DEV") != "true" {
+	if !cfg.LocalDev {
 		router.Use(middleware.SecurityMiddleware())
 		router.Use(middleware.RateLimitMiddleware())
 	}
@@ -50,27 +80,103 @@ func main() {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
-	// Serve uploaded files
-	router.Static("/uploads", "./uploads")
+	// Blob storage for uploads; the local backend serves its own files
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to configure storage: %v", err)
+	}
+	if local, ok := store.(*storage.LocalStore); ok {
+		router.Static(local.PublicPath(), local.Root())
+		router.GET(local.SignedPath()+"/*key", gin.WrapH(http.StripPrefix(local.SignedPath(), local)))
+	}
+
+	// Private storage for identity documents, only readable through signed URLs
+	privateStore, err := storage.NewPrivate(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to configure private storage: %v", err)
+	}
+	if local, ok := privateStore.(*storage.LocalStore); ok {
+		router.GET(local.SignedPath()+"/*key", gin.WrapH(http.StripPrefix(local.SignedPath(), local)))
+	}
+	go func() {
+		if err := api.MigrateLegacyVerificationDocuments(context.Background(), db, store, privateStore); err != nil {
+			log.Printf("Failed to move legacy verification documents to private storage: %v", err)
+		}
+		for {
+			if err := api.PurgeVerificationDocuments(context.Background(), db, privateStore); err != nil {
+				log.Printf("Failed to purge verification documents: %v", err)
+			}
+			time.Sleep(time.Hour)
+		}
+	}()
+
+	// Payment provider for wallet top-ups and withdrawals
+	var paymentProvider payments.PaymentProvider
+	switch cfg.PaymentProvider {
+	case "fake":
+		log.Println("Using the fake payment provider; no real money moves")
+		paymentProvider = payments.NewFakeProvider(cfg.PaymentWebhookSecret)
+	default:
+		log.Println("No payment provider configured; top-ups and withdrawals are disabled")
+	}
+
+	// SMS delivery of OTP login codes
+	var smsSender sms.SMSSender
+	switch cfg.SMSProvider {
+	case "log":
+		log.Println("Writing SMS messages to the log; none are delivered")
+		smsSender = sms.NewLogSender()
+	default:
+		log.Println("No SMS provider configured; OTP login is disabled")
+	}
+
+	// Real-time delivery of chat, notifications and bid updates
+	hub := realtime.NewHub()
+	hub.Start(context.Background())
+
+	// Event lifecycle; hooks run inside the transaction of each change
+	engine := lifecycle.New()
+	engine.OnBiddingClosed(api.DetermineWinners)
+	engine.OnBiddingClosed(notifications.BiddingClosed)
+	engine.OnStatusChange(api.ArchiveEventChat)
+	lifecycle.NewScheduler(db, engine, hub).Start(context.Background())
 
 	// API v1
 	v1 := router.Group("/api/v1")
 	{
-		api.RegisterAuthRoutes(v1)
+		api.RegisterAuthRoutes(v1, db, cfg.Auth, smsSender, hub)
+		if paymentProvider != nil {
+			api.RegisterPaymentWebhookRoutes(v1, db, paymentProvider, hub)
+		}
+		api.RegisterStreamRoutes(v1, db, hub, cfg.Auth.JWTSecret)
 
 		protected := v1.Group("")
-		protected.Use(middleware.AuthMiddleware())
+		protected.Use(middleware.AuthMiddleware(db, cfg.Auth.JWTSecret))
 		{
-			api.RegisterUserRoutes(protected)
-			api.RegisterEventRoutes(protected, db)
-			api.RegisterChatRoutes(protected, db)
-			api.RegisterUploadRoutes(protected)
-			api.RegisterApplicationRoutes(protected, db)
+			api.RegisterSessionRoutes(protected, db, hub)
+			api.RegisterUserRoutes(protected, db)
+			api.RegisterFollowRoutes(protected, db, hub)
+			api.RegisterEventRoutes(protected, db, hub, engine)
+			api.RegisterChatRoutes(protected, db, hub)
+			api.RegisterUploadRoutes(protected, store)
+			api.RegisterApplicationRoutes(protected, db, hub)
+			api.RegisterVerificationRoutes(protected, db, privateStore)
+			api.RegisterWalletRoutes(protected, db, paymentProvider, hub)
+			api.RegisterNotificationRoutes(protected, db)
+			api.RegisterSearchRoutes(protected, db)
+			api.RegisterDashboardRoutes(protected, db)
+			api.RegisterFeedRoutes(protected, db, feed.DefaultScorer())
+
+			admin := protected.Group("/admin")
+			admin.Use(middleware.RequireRole(models.RoleAdmin))
+			{
+				api.RegisterAdminRoutes(admin, db, hub, engine, privateStore)
+			}
 		}
 	}
 
 	// Start server
-	if err := router.Run("0.0.0.0:" + port); err != nil {
+	if err := router.Run("0.0.0.0:" + cfg.Port); err != nil {
 		log.Fatal("Failed to start server: ", err)
 	}
 }
@@ -0,0 +1,120 @@
+// Command migrate manages the database schema migrations.
+//
+//	migrate up             apply all pending migrations
+//	migrate down N         roll back the last N migrations
+//	migrate status         list migrations and whether they are applied
+//	migrate create NAME    add empty up and down files for a new migration
+//
+// The database is configured with the same settings as the API, see
+// internal/config.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"spotlight-backend-go/internal/config"
+	"spotlight-backend-go/internal/database"
+	"spotlight-backend-go/internal/migrate"
+	"strconv"
+)
+
+const usage = `usage:
+  migrate up             apply all pending migrations
+  migrate down N         roll back the last N migrations
+  migrate status         list migrations and whether they are applied
+  migrate create NAME    add empty up and down files for a new migration`
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	switch os.Args[1] {
+	case "up":
+		migrator := newMigrator()
+		applied, err := migrator.Up(context.Background())
+		printMigrations("Applied", applied)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+
+	case "down":
+		if len(os.Args) != 3 {
+			log.Fatal("usage: migrate down N")
+		}
+		n, err := strconv.Atoi(os.Args[2])
+		if err != nil || n < 1 {
+			log.Fatal("N must be a positive number of migrations to roll back")
+		}
+		migrator := newMigrator()
+		rolledBack, err := migrator.Down(context.Background(), n)
+		printMigrations("Rolled back", rolledBack)
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("No applied migrations")
+		}
+
+	case "status":
+		statuses, err := newMigrator().Status(context.Background())
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.Modified {
+				state += " (modified since applied)"
+			}
+			if status.Missing {
+				state += " (file missing)"
+			}
+			fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, state)
+		}
+
+	case "create":
+		if len(os.Args) != 3 {
+			log.Fatal("usage: migrate create NAME")
+		}
+		up, down, err := migrate.Create(database.MigrationsDir, os.Args[2])
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		fmt.Println("Created", up)
+		fmt.Println("Created", down)
+
+	default:
+		log.Fatal(usage)
+	}
+}
+
+func newMigrator() *migrate.Migrator {
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := database.OpenSQL(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	return migrator
+}
+
+func printMigrations(verb string, migrations []migrate.Migration) {
+	for _, m := range migrations {
+		fmt.Printf("%s %04d_%s\n", verb, m.Version, m.Name)
+	}
+}
@@ -0,0 +1,45 @@
+// Command schema inspects the database schema.
+//
+//	schema check    compare the models with the database and exit with
+//	                status 1 if they have drifted apart
+//
+// The database is configured with the same settings as the API, see
+// internal/config.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"spotlight-backend-go/internal/config"
+	"spotlight-backend-go/internal/database"
+)
+
+const usage = `usage:
+  schema check    compare the models with the database`
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) != 2 || os.Args[1] != "check" {
+		log.Fatal(usage)
+	}
+
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		log.Fatal(err)
+	}
+	problems, err := database.CheckSchema(database.InitDB(cfg))
+	if err != nil {
+		log.Fatalf("Schema check failed: %v", err)
+	}
+	if len(problems) == 0 {
+		fmt.Println("Schema matches the models")
+		return
+	}
+
+	fmt.Printf("Schema drift: %d problem(s)\n", len(problems))
+	for _, problem := range problems {
+		fmt.Printf("  %-18s %s\n", problem.Kind, problem)
+	}
+	os.Exit(1)
+}
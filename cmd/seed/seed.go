@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"spotlight-backend-go/internal/config"
 	"spotlight-backend-go/internal/database"
 )
 
@@ -9,7 +10,11 @@ func main() {
 	log.Println("Starting database seeding...")
 
 	// Initialize database
-	database.InitDB()
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		log.Fatal(err)
+	}
+	database.InitDB(cfg)
 
 	// Run seed function
 	database.SeedData()
@@ -55,6 +55,7 @@ if ! gcloud run deploy $SERVICE_NAME \
     --set-env-vars="DB_SSLMODE=disable" \
     --set-secrets="DB_PASSWORD=db-password:latest" \
     --set-secrets="JWT_SECRET=jwt-secret:latest" \
+    --set-secrets="STORAGE_SIGNING_SECRET=storage-signing-secret:latest" \
     --add-cloudsql-instances "$PROJECT_ID:$REGION:spotlight-postgres"; then
     print_error "Failed to deploy to Cloud Run!"
     exit 1
@@ -63,6 +63,11 @@ echo "$(openssl rand -base64 32)" | gcloud secrets create jwt-secret \
   --data-file=- 2>/dev/null || \
   echo "$(openssl rand -base64 32)" | gcloud secrets versions add jwt-secret --data-file=-
 
+# Secret signing the URLs of stored files
+openssl rand -base64 32 | gcloud secrets create storage-signing-secret \
+  --replication-policy="automatic" \
+  --data-file=- 2>/dev/null || echo "Storage signing secret already exists."
+
 # Create a service account for Cloud Run
 echo "Creating service account for Cloud Run..."
 SERVICE_ACCOUNT="spotlight-api-sa"
@@ -90,7 +95,7 @@ gcloud run deploy $SERVICE_NAME \
   --memory=512Mi \
   --timeout=300s \
   --set-env-vars="DB_PORT=5432,DB_USER=postgres,DB_NAME=spotlight,DB_SSLMODE=disable,DB_HOST=/cloudsql/$PROJECT_ID:$REGION:$INSTANCE_NAME" \
-  --set-secrets="DB_PASSWORD=db-password:latest,JWT_SECRET=jwt-secret:latest" \
+  --set-secrets="DB_PASSWORD=db-password:latest,JWT_SECRET=jwt-secret:latest,STORAGE_SIGNING_SECRET=storage-signing-secret:latest" \
   --add-cloudsql-instances="$PROJECT_ID:$REGION:$INSTANCE_NAME"
 
 echo "Deployment completed successfully!" 
\ No newline at end of file
@@ -50,7 +50,7 @@ done
 
 # Run migrations
 echo "Running database migrations..."
-PGPASSWORD=$DB_PASSWORD psql -h localhost -p $PROXY_PORT -U $DB_USER -d $DB_NAME -f migrations/complete_schema.sql
+DB_HOST=localhost DB_PORT=$PROXY_PORT DB_USER=$DB_USER DB_PASSWORD=$DB_PASSWORD DB_NAME=$DB_NAME go run ./cmd/migrate up
 
 # Check if migration was successful
 if [ $? -eq 0 ]; then
@@ -0,0 +1,324 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"spotlight-backend-go/internal/lifecycle"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/realtime"
+	"spotlight-backend-go/internal/storage"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// deleteAllUsersConfirmation must be passed as the confirm query parameter
+// to wipe all non-admin users
+const deleteAllUsersConfirmation = "DELETE_ALL_USERS"
+
+// RegisterAdminRoutes registers admin-only routes. The group must already be
+// guarded by AuthMiddleware and RequireRole(models.RoleAdmin).
+func RegisterAdminRoutes(router *gin.RouterGroup, db *gorm.DB, hub *realtime.Hub, engine *lifecycle.Engine, documents storage.BlobStore) {
+	userGroup := router.Group("/users")
+	{
+		userGroup.GET("", adminListUsers(db))
+		userGroup.GET("/:id", adminGetUser(db))
+		userGroup.POST("/:id/suspend", suspendUser(db, hub))
+		userGroup.POST("/:id/unsuspend", unsuspendUser(db))
+		userGroup.DELETE("/:id", adminDeleteUser(db, hub, engine))
+		userGroup.DELETE("/all", deleteAllUsers(db, hub, engine))
+	}
+
+	registerAdminVerificationRoutes(router, db, hub, documents)
+
+	router.GET("/wallets/reconcile", reconcileWallets(db))
+}
+
+// adminListUsers returns a page of users, optionally filtered by role,
+// suspension state or a name/email/username search
+func adminListUsers(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit := parsePagination(c)
+
+		query := db.Model(&models.User{})
+		if role := c.Query("role"); role != "" {
+			query = query.Where("role = ?", role)
+		}
+		switch c.Query("suspended") {
+		case "true":
+			query = query.Where("suspended_at IS NOT NULL")
+		case "false":
+			query = query.Where("suspended_at IS NULL")
+		}
+		if q := strings.TrimSpace(c.Query("q")); q != "" {
+			like := "%" + strings.ToLower(q) + "%"
+			query = query.Where("LOWER(name) LIKE ? OR LOWER(email) LIKE ? OR LOWER(username) LIKE ?", like, like, like)
+		}
+
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+			return
+		}
+
+		var users []models.User
+		if err := query.Order("created_at DESC").
+			Offset((page - 1) * limit).Limit(limit).
+			Find(&users).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"users": users,
+			"total": total,
+			"page":  page,
+			"limit": limit,
+		})
+	}
+}
+
+// adminGetUser returns a single user including moderation fields
+func adminGetUser(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user models.User
+		if err := db.First(&user, "id = ?", c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+// suspendUser blocks a user from using the API, revokes their sessions and
+// closes their streams
+func suspendUser(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Reason string `json:"reason" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A suspension reason is required"})
+			return
+		}
+
+		id := c.Param("id")
+		if id == c.GetString("user_id") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot suspend yourself"})
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, "id = ?", id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if user.Role == models.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Administrators cannot be suspended"})
+			return
+		}
+
+		now := time.Now()
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&user).Updates(map[string]interface{}{
+				"suspended_at":     now,
+				"suspended_reason": req.Reason,
+			}).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.Session{}).
+				Where("user_id = ? AND revoked_at IS NULL", user.ID).
+				Updates(map[string]interface{}{
+					"revoked_at":     now,
+					"revoked_reason": "suspended",
+				}).Error
+		})
+		if err != nil {
+			log.Printf("Error suspending user %s: %v", user.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suspend user"})
+			return
+		}
+		hub.Disconnect(user.ID)
+
+		log.Printf("User %s suspended by admin %s: %s", user.ID, c.GetString("user_id"), req.Reason)
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+// unsuspendUser lifts a suspension
+func unsuspendUser(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user models.User
+		if err := db.First(&user, "id = ?", c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		if err := db.Model(&user).Updates(map[string]interface{}{
+			"suspended_at":     nil,
+			"suspended_reason": "",
+		}).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsuspend user"})
+			return
+		}
+
+		log.Printf("User %s unsuspended by admin %s", user.ID, c.GetString("user_id"))
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+// adminDeleteUser deletes a single non-admin user. The account is
+// anonymized rather than removed; see anonymizeUser.
+func adminDeleteUser(db *gorm.DB, hub *realtime.Hub, engine *lifecycle.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user models.User
+		if err := db.First(&user, "id = ?", c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if user.Role == models.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Administrators cannot be deleted"})
+			return
+		}
+
+		if err := hub.Transaction(db, func(tx *gorm.DB) error {
+			return anonymizeUser(tx, engine, &user)
+		}); err != nil {
+			log.Printf("Error deleting user %s: %v", user.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+			return
+		}
+		hub.Disconnect(user.ID)
+
+		log.Printf("User %s deleted by admin %s", user.ID, c.GetString("user_id"))
+		c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+	}
+}
+
+// deleteAllUsers deletes every non-admin user the way adminDeleteUser does.
+// It requires an explicit confirmation query parameter to guard against
+// accidental calls.
+func deleteAllUsers(db *gorm.DB, hub *realtime.Hub, engine *lifecycle.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Query("confirm") != deleteAllUsersConfirmation {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Pass confirm=%s to delete all users", deleteAllUsersConfirmation),
+			})
+			return
+		}
+
+		var users []models.User
+		var deleted int
+		err := db.Where("role <> ?", models.RoleAdmin).FindInBatches(&users, 100, func(batch *gorm.DB, _ int) error {
+			for i := range users {
+				if err := hub.Transaction(db, func(tx *gorm.DB) error {
+					return anonymizeUser(tx, engine, &users[i])
+				}); err != nil {
+					return err
+				}
+				hub.Disconnect(users[i].ID)
+				deleted++
+			}
+			return nil
+		}).Error
+		if err != nil {
+			log.Printf("Error deleting all users after %d: %v", deleted, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete users"})
+			return
+		}
+
+		log.Printf("Admin %s deleted %d users", c.GetString("user_id"), deleted)
+		c.JSON(http.StatusOK, gin.H{
+			"message": fmt.Sprintf("Successfully deleted %d users", deleted),
+			"count":   deleted,
+		})
+	}
+}
+
+// anonymizeUser deletes a user's account without removing the row. The
+// transactions, bids and events that refer to it must stay intact, so the
+// user's pending bids are withdrawn, the events they host cancelled, the
+// personal data erased, the follows and sessions removed and the row
+// soft-deleted.
+func anonymizeUser(tx *gorm.DB, engine *lifecycle.Engine, user *models.User) error {
+	now := time.Now()
+
+	// Return the escrow of the user's pending bids to their wallet
+	var pendingIDs []string
+	if err := tx.Model(&models.Application{}).
+		Where("fan_id = ? AND status = ?", user.ID, models.ApplicationStatusPending).
+		Pluck("id", &pendingIDs).Error; err != nil {
+		return err
+	}
+	for _, id := range pendingIDs {
+		var application models.Application
+		event, err := lockApplicationEvent(tx, id, &application)
+		if err != nil {
+			return err
+		}
+		if application.Status != models.ApplicationStatusPending {
+			continue
+		}
+		if err := withdrawApplicationTx(tx, event, &application); err != nil {
+			return err
+		}
+	}
+
+	// Cancel the events the user hosts so their fans are refunded
+	var hosted []models.Event
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("host_id = ? AND status IN ?", user.ID, []models.EventStatus{models.EventStatusUpcoming, models.EventStatusOngoing}).
+		Find(&hosted).Error; err != nil {
+		return err
+	}
+	for i := range hosted {
+		if _, err := cancelEventTx(tx, engine, &hosted[i], "The host's account was deleted"); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(user).Updates(map[string]interface{}{
+		"name":              "Deleted user",
+		"username":          "deleted-" + user.ID,
+		"email":             "deleted-" + user.ID + "@deleted.invalid",
+		"password":          "",
+		"avatar_url":        "",
+		"bio":               "",
+		"media_gallery":     nil,
+		"profile_photos":    nil,
+		"location":          "",
+		"work":              "",
+		"education":         "",
+		"mobile_number":     "",
+		"government_id_url": "",
+		"cover_photo_url":   "",
+		"instagram_handle":  "",
+		"follower_count":    0,
+	}).Error; err != nil {
+		return err
+	}
+	// Unfollow everyone the user followed, keeping their follower counts in
+	// step as unfollowUser does
+	if err := tx.Model(&models.User{}).
+		Where("id IN (?)", tx.Model(&models.Follow{}).Select("following_id").Where("follower_id = ?", user.ID)).
+		UpdateColumn("follower_count", gorm.Expr("GREATEST(COALESCE(follower_count, 0) - 1, 0)")).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("follower_id = ? OR following_id = ?", user.ID, user.ID).
+		Delete(&models.Follow{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", user.ID).
+		Updates(map[string]interface{}{
+			"revoked_at":     now,
+			"revoked_reason": "deleted",
+		}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(user).Error
+}
@@ -1,19 +1,48 @@
 package api
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/notifications"
+	"spotlight-backend-go/internal/realtime"
+	"spotlight-backend-go/internal/wallet"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// getApplicationsByEventID fetches applications for a specific event
+var (
+	errEventFull             = errors.New("event is full")
+	errApplicationNotPending = errors.New("application is not pending")
+	errNotEventHost          = errors.New("not the event host")
+	errBiddingOpen           = errors.New("bidding is still open")
+)
+
+// getApplicationsByEventID fetches the active applications for a specific
+// event; withdrawn bids are left out. The host sees every application and
+// anyone else only their own.
 func getApplicationsByEventID(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		eventID := c.Param("eventId")
+		userID := c.GetString("user_id")
+
+		var event models.Event
+		if err := db.First(&event, "id = ?", eventID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+
+		query := db.Where("event_id = ? AND status <> ?", eventID, models.ApplicationStatusWithdrawn)
+		if event.HostID != userID {
+			query = query.Where("fan_id = ?", userID)
+		}
+
 		var applications []models.Application
-		if err := db.Where("event_id = ?", eventID).Find(&applications).Error; err != nil {
+		if err := query.Find(&applications).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
 			return
 		}
@@ -21,9 +50,326 @@ func getApplicationsByEventID(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-func RegisterApplicationRoutes(router *gin.RouterGroup, db *gorm.DB) {
+func RegisterApplicationRoutes(router *gin.RouterGroup, db *gorm.DB, hub *realtime.Hub) {
 	applicationGroup := router.Group("/applications")
 	{
 		applicationGroup.GET("/event/:eventId", getApplicationsByEventID(db))
+		applicationGroup.POST("/event/:eventId/accept-top", acceptTopBids(db, hub))
+		applicationGroup.GET("/:id/bids", getApplicationBids(db))
+		applicationGroup.POST("/:id/accept", acceptApplication(db, hub))
+		applicationGroup.POST("/:id/reject", rejectApplication(db, hub))
+	}
+}
+
+// getApplicationBids returns the bid history behind an application, newest
+// first. Only the event host and the fan who bid may see it.
+func getApplicationBids(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+
+		var application models.Application
+		if err := db.First(&application, "id = ?", c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+			return
+		}
+		if application.FanID != userID {
+			var event models.Event
+			if err := db.Select("id", "host_id").First(&event, "id = ?", application.EventID).Error; err != nil || event.HostID != userID {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can view bids on this event"})
+				return
+			}
+		}
+
+		history, err := bidHistory(db, application.EventID, application.FanID)
+		if err != nil {
+			log.Printf("Error fetching bid history for application %s: %v", application.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bid history"})
+			return
+		}
+		c.JSON(http.StatusOK, history)
+	}
+}
+
+// acceptApplication lets the event host accept a pending application, which
+// adds the fan as an attendee if the event still has capacity. Sealed and
+// ascending auctions must have closed first.
+func acceptApplication(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+
+		var application models.Application
+		err := hub.Transaction(db, func(tx *gorm.DB) error {
+			event, err := lockApplication(tx, c.Param("id"), userID, &application)
+			if err != nil {
+				return err
+			}
+			// Auctions are settled when bidding closes; only fixed price
+			// places can be accepted while it is open
+			if event.AuctionMode != models.AuctionModeFixed && event.BiddingClosedAt == nil {
+				return errBiddingOpen
+			}
+			return acceptApplicationTx(tx, event, &application)
+		})
+		if err != nil {
+			respondApplicationError(c, err, "Failed to accept application")
+			return
+		}
+		c.JSON(http.StatusOK, application)
+	}
+}
+
+// rejectApplication lets the event host reject a pending application
+func rejectApplication(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+
+		var application models.Application
+		err := hub.Transaction(db, func(tx *gorm.DB) error {
+			event, err := lockApplication(tx, c.Param("id"), userID, &application)
+			if err != nil {
+				return err
+			}
+			return rejectApplicationTx(tx, event, &application)
+		})
+		if err != nil {
+			respondApplicationError(c, err, "Failed to reject application")
+			return
+		}
+		c.JSON(http.StatusOK, application)
+	}
+}
+
+// acceptTopBids accepts the highest pending bids for an event once bidding
+// has closed. Count defaults to the remaining capacity and is capped by it.
+// Ties are broken by who bid first.
+func acceptTopBids(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+
+		var req struct {
+			Count           int  `json:"count"`
+			RejectRemaining bool `json:"reject_remaining"`
+		}
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if req.Count < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Count must not be negative"})
+			return
+		}
+
+		var accepted, rejected []models.Application
+		err := hub.Transaction(db, func(tx *gorm.DB) error {
+			event, err := lockHostedEvent(tx, c.Param("eventId"), userID)
+			if err != nil {
+				return err
+			}
+			if event.BidDeadline.After(time.Now()) {
+				return errBiddingOpen
+			}
+
+			remaining, err := remainingCapacity(tx, event)
+			if err != nil {
+				return err
+			}
+			count := remaining
+			if req.Count > 0 && req.Count < count {
+				count = req.Count
+			}
+
+			var pending []models.Application
+			if err := tx.Where("event_id = ? AND status = ?", event.ID, models.ApplicationStatusPending).
+				Order(winningBidOrder).
+				Find(&pending).Error; err != nil {
+				return err
+			}
+
+			for i := range pending {
+				if i < count {
+					if err := acceptApplicationTx(tx, event, &pending[i]); err != nil {
+						return err
+					}
+					accepted = append(accepted, pending[i])
+				} else if req.RejectRemaining {
+					if err := rejectApplicationTx(tx, event, &pending[i]); err != nil {
+						return err
+					}
+					rejected = append(rejected, pending[i])
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			respondApplicationError(c, err, "Failed to accept bids")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"accepted": accepted,
+			"rejected": rejected,
+		})
+	}
+}
+
+// lockHostedEvent loads an event with a row lock so concurrent acceptances
+// cannot overfill it, and checks that the user is its host
+func lockHostedEvent(tx *gorm.DB, eventID string, userID string) (*models.Event, error) {
+	var event models.Event
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&event, "id = ?", eventID).Error; err != nil {
+		return nil, err
+	}
+	if event.HostID != userID {
+		return nil, errNotEventHost
+	}
+	return &event, nil
+}
+
+// lockApplication loads an application for a host decision and checks
+// that the user hosts its event; see lockApplicationEvent
+func lockApplication(tx *gorm.DB, applicationID string, userID string, application *models.Application) (*models.Event, error) {
+	event, err := lockApplicationEvent(tx, applicationID, application)
+	if err != nil {
+		return nil, err
+	}
+	if event.HostID != userID {
+		return nil, errNotEventHost
+	}
+	return event, nil
+}
+
+// lockApplicationEvent locks an application's event, as acceptTopBids
+// does, and then reads the application again under a row lock so
+// concurrent decisions see each other's result
+func lockApplicationEvent(tx *gorm.DB, applicationID string, application *models.Application) (*models.Event, error) {
+	if err := tx.Select("id", "event_id").First(application, "id = ?", applicationID).Error; err != nil {
+		return nil, err
+	}
+	var event models.Event
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&event, "id = ?", application.EventID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(application, "id = ?", applicationID).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// decideApplication moves a pending application to status. The update only
+// matches a pending row, so a decision that lost a race is refused.
+func decideApplication(tx *gorm.DB, application *models.Application, status models.ApplicationStatus) error {
+	result := tx.Model(&models.Application{}).
+		Where("id = ? AND status = ?", application.ID, models.ApplicationStatusPending).
+		Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errApplicationNotPending
+	}
+	application.Status = status
+	return nil
+}
+
+// remainingCapacity returns how many more attendees the event can take
+func remainingCapacity(tx *gorm.DB, event *models.Event) (int, error) {
+	var attendeeCount int64
+	if err := tx.Model(&models.EventAttendee{}).Where("event_id = ?", event.ID).Count(&attendeeCount).Error; err != nil {
+		return 0, err
+	}
+	remaining := event.Capacity - int(attendeeCount)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, nil
+}
+
+// acceptApplicationTx marks an application accepted and adds the fan as an
+// attendee. The event row must be locked by the caller.
+func acceptApplicationTx(tx *gorm.DB, event *models.Event, application *models.Application) error {
+	if application.Status != models.ApplicationStatusPending {
+		return errApplicationNotPending
+	}
+
+	remaining, err := remainingCapacity(tx, event)
+	if err != nil {
+		return err
+	}
+	if remaining == 0 {
+		return errEventFull
+	}
+
+	if err := decideApplication(tx, application, models.ApplicationStatusAccepted); err != nil {
+		return err
+	}
+
+	attendee := models.EventAttendee{
+		EventID:   event.ID,
+		UserID:    application.FanID,
+		CreatedAt: time.Now(),
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&attendee).Error; err != nil {
+		return err
+	}
+
+	// Pay the escrowed bid to the host
+	if err := wallet.CaptureBid(tx, application.FanID, event.HostID, event.ID, "Accepted bid for "+event.Title); err != nil {
+		return err
+	}
+
+	realtime.Enqueue(tx, application.FanID, realtime.EventBidUpdate, application)
+	if err := notifications.ApplicationDecided(tx, application, event); err != nil {
+		return err
+	}
+
+	log.Printf("Application %s accepted for event %s", application.ID, event.ID)
+	return nil
+}
+
+// rejectApplicationTx marks a pending application rejected
+func rejectApplicationTx(tx *gorm.DB, event *models.Event, application *models.Application) error {
+	if application.Status != models.ApplicationStatusPending {
+		return errApplicationNotPending
+	}
+
+	if err := decideApplication(tx, application, models.ApplicationStatusRejected); err != nil {
+		return err
+	}
+
+	// Return the escrowed bid to the fan
+	if err := wallet.ReleaseBid(tx, application.FanID, event.ID, "Bid rejected for "+event.Title); err != nil {
+		return err
+	}
+
+	realtime.Enqueue(tx, application.FanID, realtime.EventBidUpdate, application)
+	if err := notifications.ApplicationDecided(tx, application, event); err != nil {
+		return err
+	}
+
+	log.Printf("Application %s rejected for event %s", application.ID, event.ID)
+	return nil
+}
+
+// respondApplicationError maps application workflow errors to responses
+func respondApplicationError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application or event not found"})
+	case errNotEventHost:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can manage applications for this event"})
+	case errApplicationNotPending:
+		c.JSON(http.StatusConflict, gin.H{"error": "Application has already been decided"})
+	case errEventFull:
+		c.JSON(http.StatusConflict, gin.H{"error": "Event is full"})
+	case errBiddingOpen:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bidding is still open for this event"})
+	default:
+		log.Printf("%s: %v", fallback, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
 	}
 }
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/realtime"
+	"spotlight-backend-go/internal/schemas"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultMinIncrement is used for ascending auctions created without one
+	defaultMinIncrement = 1.0
+	// antiSnipeWindow is how close to the deadline an ascending bid extends
+	// it, and by how much, so rivals always get a chance to respond
+	antiSnipeWindow = 2 * time.Minute
+	// winningBidOrder ranks applications: highest bid first, earlier bid on a tie
+	winningBidOrder = "bid_amount DESC, bid_at ASC, created_at ASC"
+)
+
+var (
+	errBiddingClosed     = errors.New("bidding is closed")
+	errBidDeadlinePassed = errors.New("bid deadline has passed")
+)
+
+// bidAmountError explains why a bid amount is not accepted
+type bidAmountError struct {
+	message string
+}
+
+func (e *bidAmountError) Error() string {
+	return e.message
+}
+
+// auctionBidAmount checks a bid against the event's auction rules and
+// returns the amount to hold. Fixed price bids may omit the amount.
+func auctionBidAmount(event *models.Event, amount float64) (float64, error) {
+	switch event.AuctionMode {
+	case models.AuctionModeFixed:
+		if amount == 0 || amount == event.MinBid {
+			return event.MinBid, nil
+		}
+		return 0, &bidAmountError{fmt.Sprintf("This event has a fixed price of %.2f", event.MinBid)}
+	case models.AuctionModeAscending:
+		minimum := event.MinBid
+		if event.CurrentBid > 0 {
+			minimum = event.CurrentBid + event.MinIncrement
+		}
+		if amount < minimum {
+			return 0, &bidAmountError{fmt.Sprintf("Bid must be at least %.2f", minimum)}
+		}
+		return amount, nil
+	default:
+		if amount <= 0 || amount < event.MinBid {
+			return 0, &bidAmountError{fmt.Sprintf("Bid must be at least the minimum bid of %.2f", event.MinBid)}
+		}
+		return amount, nil
+	}
+}
+
+// raiseCurrentBid records a new leading bid on an ascending auction. A bid
+// in the last antiSnipeWindow pushes the deadline back, but never past the
+// start of the event. The other bidders are told about the new price.
+func raiseCurrentBid(tx *gorm.DB, event *models.Event, bidderID string, amount float64, now time.Time) error {
+	updates := map[string]interface{}{"current_bid": amount}
+	event.CurrentBid = amount
+	if deadline, extended := antiSnipeDeadline(event, now); extended {
+		event.BidDeadline = deadline
+		updates["bid_deadline"] = deadline
+	}
+	if err := tx.Model(&models.Event{}).Where("id = ?", event.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	return publishAuctionUpdate(tx, event, bidderID)
+}
+
+// antiSnipeDeadline returns the deadline after a bid at now: the bid
+// deadline, or for a bid in the last antiSnipeWindow now plus the window,
+// capped at the start of the event. extended reports whether it moved.
+func antiSnipeDeadline(event *models.Event, now time.Time) (deadline time.Time, extended bool) {
+	if event.BidDeadline.Sub(now) >= antiSnipeWindow {
+		return event.BidDeadline, false
+	}
+	deadline = now.Add(antiSnipeWindow)
+	if deadline.After(event.Date) {
+		deadline = event.Date
+	}
+	if !deadline.After(event.BidDeadline) {
+		return event.BidDeadline, false
+	}
+	return deadline, true
+}
+
+// resetCurrentBid sets the current bid of an ascending auction back to the
+// highest pending bid after one was withdrawn
+func resetCurrentBid(tx *gorm.DB, event *models.Event, bidderID string) error {
+	var highest float64
+	if err := tx.Model(&models.Application{}).
+		Where("event_id = ? AND status = ?", event.ID, models.ApplicationStatusPending).
+		Select("COALESCE(MAX(bid_amount), 0)").
+		Scan(&highest).Error; err != nil {
+		return err
+	}
+	if highest == event.CurrentBid {
+		return nil
+	}
+	event.CurrentBid = highest
+	if err := tx.Model(&models.Event{}).Where("id = ?", event.ID).Update("current_bid", highest).Error; err != nil {
+		return err
+	}
+	return publishAuctionUpdate(tx, event, bidderID)
+}
+
+// publishAuctionUpdate tells the host and the other pending bidders about
+// the event's current bid and deadline
+func publishAuctionUpdate(tx *gorm.DB, event *models.Event, bidderID string) error {
+	var bidderIDs []string
+	if err := tx.Model(&models.Application{}).
+		Where("event_id = ? AND status = ? AND fan_id <> ?", event.ID, models.ApplicationStatusPending, bidderID).
+		Pluck("fan_id", &bidderIDs).Error; err != nil {
+		return err
+	}
+	update := schemas.AuctionUpdate{EventID: event.ID, CurrentBid: event.CurrentBid, BidDeadline: event.BidDeadline}
+	for _, id := range append(bidderIDs, event.HostID) {
+		realtime.Enqueue(tx, id, realtime.EventAuctionUpdate, update)
+	}
+	return nil
+}
+
+// DetermineWinners is a lifecycle hook that settles sealed and ascending
+// auctions when bidding closes. The highest pending bids win up to the
+// remaining capacity, earlier bids win ties, and the rest are rejected with
+// their escrow released.
+func DetermineWinners(tx *gorm.DB, event *models.Event) error {
+	if event.AuctionMode == models.AuctionModeFixed {
+		return nil
+	}
+
+	remaining, err := remainingCapacity(tx, event)
+	if err != nil {
+		return err
+	}
+	var pending []models.Application
+	if err := tx.Where("event_id = ? AND status = ?", event.ID, models.ApplicationStatusPending).
+		Order(winningBidOrder).
+		Find(&pending).Error; err != nil {
+		return err
+	}
+	for i := range pending {
+		if i < remaining {
+			err = acceptApplicationTx(tx, event, &pending[i])
+		} else {
+			err = rejectApplicationTx(tx, event, &pending[i])
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	var top models.Application
+	err = tx.Where("event_id = ? AND status = ?", event.ID, models.ApplicationStatusAccepted).
+		Order(winningBidOrder).
+		First(&top).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	event.CurrentBid = top.BidAmount
+	event.WinnerID = &top.FanID
+	return tx.Model(&models.Event{}).Where("id = ?", event.ID).Updates(map[string]interface{}{
+		"current_bid": top.BidAmount,
+		"winner_id":   top.FanID,
+	}).Error
+}
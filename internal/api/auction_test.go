@@ -0,0 +1,189 @@
+package api
+
+import (
+	"spotlight-backend-go/internal/models"
+	"testing"
+	"time"
+)
+
+func TestAuctionBidAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   models.Event
+		amount  float64
+		want    float64
+		wantErr bool
+	}{
+		{
+			name:   "fixed price without amount",
+			event:  models.Event{AuctionMode: models.AuctionModeFixed, MinBid: 50},
+			amount: 0,
+			want:   50,
+		},
+		{
+			name:   "fixed price with the price",
+			event:  models.Event{AuctionMode: models.AuctionModeFixed, MinBid: 50},
+			amount: 50,
+			want:   50,
+		},
+		{
+			name:    "fixed price with another amount",
+			event:   models.Event{AuctionMode: models.AuctionModeFixed, MinBid: 50},
+			amount:  60,
+			wantErr: true,
+		},
+		{
+			name:   "first ascending bid at the minimum",
+			event:  models.Event{AuctionMode: models.AuctionModeAscending, MinBid: 20, MinIncrement: 5},
+			amount: 20,
+			want:   20,
+		},
+		{
+			name:    "first ascending bid below the minimum",
+			event:   models.Event{AuctionMode: models.AuctionModeAscending, MinBid: 20, MinIncrement: 5},
+			amount:  19,
+			wantErr: true,
+		},
+		{
+			name:   "ascending bid beating the current bid by the increment",
+			event:  models.Event{AuctionMode: models.AuctionModeAscending, MinBid: 20, MinIncrement: 5, CurrentBid: 30},
+			amount: 35,
+			want:   35,
+		},
+		{
+			name:    "ascending bid short of the increment",
+			event:   models.Event{AuctionMode: models.AuctionModeAscending, MinBid: 20, MinIncrement: 5, CurrentBid: 30},
+			amount:  34,
+			wantErr: true,
+		},
+		{
+			name:   "sealed bid at the minimum",
+			event:  models.Event{AuctionMode: models.AuctionModeSealed, MinBid: 20},
+			amount: 20,
+			want:   20,
+		},
+		{
+			name:    "sealed bid below the minimum",
+			event:   models.Event{AuctionMode: models.AuctionModeSealed, MinBid: 20},
+			amount:  10,
+			wantErr: true,
+		},
+		{
+			name:    "sealed bid without amount",
+			event:   models.Event{AuctionMode: models.AuctionModeSealed},
+			amount:  0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := auctionBidAmount(&tt.event, tt.amount)
+			if tt.wantErr {
+				if _, ok := err.(*bidAmountError); !ok {
+					t.Fatalf("auctionBidAmount() error = %v, want a bidAmountError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("auctionBidAmount() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("auctionBidAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAntiSnipeDeadline(t *testing.T) {
+	now := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		bidDeadline  time.Time
+		eventDate    time.Time
+		want         time.Time
+		wantExtended bool
+	}{
+		{
+			name:        "bid well before the deadline",
+			bidDeadline: now.Add(time.Hour),
+			eventDate:   now.Add(24 * time.Hour),
+			want:        now.Add(time.Hour),
+		},
+		{
+			name:        "bid exactly one window before the deadline",
+			bidDeadline: now.Add(antiSnipeWindow),
+			eventDate:   now.Add(24 * time.Hour),
+			want:        now.Add(antiSnipeWindow),
+		},
+		{
+			name:         "bid inside the window",
+			bidDeadline:  now.Add(30 * time.Second),
+			eventDate:    now.Add(24 * time.Hour),
+			want:         now.Add(antiSnipeWindow),
+			wantExtended: true,
+		},
+		{
+			name:         "extension capped at the event start",
+			bidDeadline:  now.Add(30 * time.Second),
+			eventDate:    now.Add(time.Minute),
+			want:         now.Add(time.Minute),
+			wantExtended: true,
+		},
+		{
+			name:        "event starts at the deadline",
+			bidDeadline: now.Add(30 * time.Second),
+			eventDate:   now.Add(30 * time.Second),
+			want:        now.Add(30 * time.Second),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := models.Event{BidDeadline: tt.bidDeadline, Date: tt.eventDate}
+			got, extended := antiSnipeDeadline(&event, now)
+			if !got.Equal(tt.want) || extended != tt.wantExtended {
+				t.Fatalf("antiSnipeDeadline() = %v, %v, want %v, %v", got, extended, tt.want, tt.wantExtended)
+			}
+		})
+	}
+}
+
+func TestAscendingBidsNearTheDeadline(t *testing.T) {
+	start := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	event := models.Event{
+		AuctionMode:  models.AuctionModeAscending,
+		MinBid:       10,
+		MinIncrement: 2,
+		BidDeadline:  start.Add(time.Minute),
+		Date:         start.Add(time.Hour),
+	}
+
+	// Each rival bids a little before the latest deadline, pushing it back
+	bids := []struct {
+		at     time.Time
+		amount float64
+	}{
+		{at: start.Add(50 * time.Second), amount: 10},
+		{at: start.Add(2 * time.Minute), amount: 12},
+		{at: start.Add(3*time.Minute + 30*time.Second), amount: 15},
+	}
+	for _, bid := range bids {
+		if !bid.at.Before(event.BidDeadline) {
+			t.Fatalf("bid at %v after the deadline %v", bid.at, event.BidDeadline)
+		}
+		amount, err := auctionBidAmount(&event, bid.amount)
+		if err != nil {
+			t.Fatalf("bid of %v: %v", bid.amount, err)
+		}
+		event.CurrentBid = amount
+		if deadline, extended := antiSnipeDeadline(&event, bid.at); extended {
+			event.BidDeadline = deadline
+		}
+		if want := bid.at.Add(antiSnipeWindow); !event.BidDeadline.Equal(want) {
+			t.Fatalf("deadline after bid at %v = %v, want %v", bid.at, event.BidDeadline, want)
+		}
+	}
+
+	if _, err := auctionBidAmount(&event, 16); err == nil {
+		t.Fatal("accepted a bid below the current bid plus the increment")
+	}
+}
@@ -0,0 +1,325 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/notifications"
+	"spotlight-backend-go/internal/realtime"
+	"spotlight-backend-go/internal/wallet"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errActiveBidExists = errors.New("fan already has an active bid")
+	errNoActiveBid     = errors.New("fan has no active bid")
+)
+
+// placeBidRequest is the body of placing or raising a bid
+type placeBidRequest struct {
+	Amount  float64 `json:"amount"`
+	Message string  `json:"message"`
+}
+
+// placeBid places a fan's first bid on an event, or a new one after a
+// withdrawal. The amount is checked against the event's auction mode; fixed
+// price bids are accepted at once while places remain.
+func placeBid(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		submitBid(c, db, hub, false)
+	}
+}
+
+// raiseBid replaces a fan's active bid with a higher one, keeping the old
+// amount in the bid history and holding only the difference
+func raiseBid(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		submitBid(c, db, hub, true)
+	}
+}
+
+// submitBid records a new active bid for the current user, superseding the
+// previous one when raise is set
+func submitBid(c *gin.Context, db *gorm.DB, hub *realtime.Hub, raise bool) {
+	id := c.Param("id")
+	userID := strings.TrimSpace(c.GetString("user_id"))
+
+	// Check if user is authenticated
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req placeBidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var bid models.Bid
+	err := hub.Transaction(db, func(tx *gorm.DB) error {
+		event, now, err := lockOpenEvent(tx, id)
+		if err != nil {
+			return err
+		}
+		amount, err := auctionBidAmount(event, req.Amount)
+		if err != nil {
+			return err
+		}
+
+		var application models.Application
+		err = tx.Where("event_id = ? AND fan_id = ?", event.ID, userID).First(&application).Error
+		isNew := err == gorm.ErrRecordNotFound
+		switch {
+		case isNew:
+			if raise {
+				return errNoActiveBid
+			}
+			application = models.Application{
+				ID:      uuid.New().String(),
+				EventID: event.ID,
+				FanID:   userID,
+			}
+		case err != nil:
+			return err
+		case application.Status == models.ApplicationStatusWithdrawn:
+			if raise {
+				return errNoActiveBid
+			}
+		case application.Status != models.ApplicationStatusPending:
+			// Bids can't change an application that has already been decided
+			return errApplicationNotPending
+		case !raise:
+			return errActiveBidExists
+		case amount <= application.BidAmount:
+			return &bidAmountError{fmt.Sprintf("Bid must be higher than your current bid of %.2f", application.BidAmount)}
+		}
+
+		application.Status = models.ApplicationStatusPending
+		application.BidAmount = amount
+		application.BidAt = now
+		if req.Message != "" {
+			application.Message = req.Message
+		}
+		if isNew {
+			err = tx.Create(&application).Error
+		} else {
+			err = tx.Save(&application).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Bid{}).
+			Where("event_id = ? AND user_id = ? AND status = ?", event.ID, userID, models.BidStatusActive).
+			Update("status", models.BidStatusSuperseded).Error; err != nil {
+			return err
+		}
+		bid = models.Bid{
+			ID:            uuid.New().String(),
+			EventID:       event.ID,
+			UserID:        userID,
+			ApplicationID: &application.ID,
+			Amount:        amount,
+			Status:        models.BidStatusActive,
+		}
+		if err := tx.Create(&bid).Error; err != nil {
+			return err
+		}
+
+		// Hold the bid amount in escrow
+		if err := wallet.HoldBid(tx, userID, event.ID, bid.ID, amount); err != nil {
+			return err
+		}
+
+		if event.AuctionMode == models.AuctionModeAscending {
+			if err := raiseCurrentBid(tx, event, userID, amount, now); err != nil {
+				return err
+			}
+		}
+
+		// Keep the fan's other devices and the host's dashboard current
+		realtime.Enqueue(tx, userID, realtime.EventBidUpdate, application)
+		realtime.Enqueue(tx, event.HostID, realtime.EventBidUpdate, application)
+
+		fan := c.MustGet("user").(*models.User)
+		if err := notifications.BidPlaced(tx, event.HostID, fan, event, bid.ID, amount); err != nil {
+			return err
+		}
+
+		if event.AuctionMode == models.AuctionModeFixed {
+			return acceptApplicationTx(tx, event, &application)
+		}
+		return nil
+	})
+	if err != nil {
+		respondBidError(c, err, id, "Failed to place bid")
+		return
+	}
+
+	status := http.StatusCreated
+	if raise {
+		status = http.StatusOK
+	}
+	c.JSON(status, bid)
+}
+
+// withdrawBid takes back the current user's pending bid before the
+// deadline. The escrow is released and the bid stays in the history.
+func withdrawBid(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		userID := c.GetString("user_id")
+
+		var application models.Application
+		err := hub.Transaction(db, func(tx *gorm.DB) error {
+			event, _, err := lockOpenEvent(tx, id)
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Where("event_id = ? AND fan_id = ?", event.ID, userID).First(&application).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return errNoActiveBid
+				}
+				return err
+			}
+			switch application.Status {
+			case models.ApplicationStatusPending:
+			case models.ApplicationStatusWithdrawn:
+				return errNoActiveBid
+			default:
+				return errApplicationNotPending
+			}
+
+			return withdrawApplicationTx(tx, event, &application)
+		})
+		if err != nil {
+			respondBidError(c, err, id, "Failed to withdraw bid")
+			return
+		}
+		c.JSON(http.StatusOK, application)
+	}
+}
+
+// withdrawApplicationTx withdraws a pending application and returns the
+// escrowed bid to the fan. The event row must be locked by the caller.
+func withdrawApplicationTx(tx *gorm.DB, event *models.Event, application *models.Application) error {
+	if err := tx.Model(&models.Application{}).
+		Where("id = ?", application.ID).
+		Update("status", models.ApplicationStatusWithdrawn).Error; err != nil {
+		return err
+	}
+	application.Status = models.ApplicationStatusWithdrawn
+
+	if err := tx.Model(&models.Bid{}).
+		Where("event_id = ? AND user_id = ? AND status = ?", event.ID, application.FanID, models.BidStatusActive).
+		Update("status", models.BidStatusWithdrawn).Error; err != nil {
+		return err
+	}
+	if err := wallet.ReleaseBid(tx, application.FanID, event.ID, "Bid withdrawn for "+event.Title); err != nil {
+		return err
+	}
+
+	// The current bid falls back to the best remaining one
+	if event.AuctionMode == models.AuctionModeAscending {
+		if err := resetCurrentBid(tx, event, application.FanID); err != nil {
+			return err
+		}
+	}
+
+	realtime.Enqueue(tx, application.FanID, realtime.EventBidUpdate, application)
+	realtime.Enqueue(tx, event.HostID, realtime.EventBidUpdate, application)
+	return nil
+}
+
+// getMyBid returns the current user's application for an event together
+// with every revision of their bid
+func getMyBid(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+
+		var application models.Application
+		if err := db.Where("event_id = ? AND fan_id = ?", c.Param("id"), userID).First(&application).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "You have not bid on this event"})
+			return
+		}
+
+		history, err := bidHistory(db, application.EventID, userID)
+		if err != nil {
+			log.Printf("Error fetching bid history for application %s: %v", application.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bid history"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"application": application,
+			"history":     history,
+		})
+	}
+}
+
+// bidHistory returns every revision of a fan's bid on an event, newest first
+func bidHistory(db *gorm.DB, eventID string, userID string) ([]models.Bid, error) {
+	history := []models.Bid{}
+	err := db.Where("event_id = ? AND user_id = ?", eventID, userID).
+		Order("created_at DESC, id DESC").
+		Find(&history).Error
+	return history, err
+}
+
+// lockOpenEvent loads an event with a row lock and checks that it still
+// takes bids. Bids on one event are serialized so the current bid, deadline
+// and capacity checks see each other's effects.
+func lockOpenEvent(tx *gorm.DB, eventID string) (*models.Event, time.Time, error) {
+	var event models.Event
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, "id = ?", eventID).Error; err != nil {
+		return nil, time.Time{}, err
+	}
+
+	now := time.Now()
+	if !event.BidDeadline.After(now) {
+		return nil, now, errBidDeadlinePassed
+	}
+	if event.Status != models.EventStatusUpcoming || event.BiddingClosedAt != nil {
+		return nil, now, errBiddingClosed
+	}
+	return &event, now, nil
+}
+
+// respondBidError maps bidding errors to responses
+func respondBidError(c *gin.Context, err error, eventID string, fallback string) {
+	var amountErr *bidAmountError
+	if errors.As(err, &amountErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": amountErr.Error()})
+		return
+	}
+	switch err {
+	case gorm.ErrRecordNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	case errBidDeadlinePassed:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bid deadline has passed"})
+	case errBiddingClosed:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bidding is closed for this event"})
+	case errActiveBidExists:
+		c.JSON(http.StatusConflict, gin.H{"error": "You already have an active bid on this event. Raise it with PUT /events/:id/bid"})
+	case errNoActiveBid:
+		c.JSON(http.StatusNotFound, gin.H{"error": "You have no active bid on this event"})
+	case errApplicationNotPending:
+		c.JSON(http.StatusConflict, gin.H{"error": "Your application has already been decided"})
+	case errEventFull:
+		c.JSON(http.StatusConflict, gin.H{"error": "Event is sold out"})
+	case wallet.ErrInsufficientFunds:
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": "Insufficient wallet balance"})
+	default:
+		log.Printf("%s on event %s: %v", fallback, eventID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -1,176 +1,578 @@
 package api
 
 import (
+	"errors"
+	"log"
 	"net/http"
+	"sort"
+	"spotlight-backend-go/internal/lifecycle"
 	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/realtime"
+	"spotlight-backend-go/internal/schemas"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// Chat represents a chat between users
-type Chat struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	User1ID   uint           `json:"user1_id"`
-	User2ID   uint           `json:"user2_id"`
-	User1     models.User    `json:"user1" gorm:"foreignKey:User1ID"`
-	User2     models.User    `json:"user2" gorm:"foreignKey:User2ID"`
-	Messages  []Message      `json:"messages"`
-	CreatedAt int64          `json:"created_at"`
-	UpdatedAt int64          `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
-}
-
-// Message represents a message in a chat
-type Message struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	ChatID    uint           `json:"chat_id"`
-	SenderID  uint           `json:"sender_id"`
-	Content   string         `json:"content"`
-	Read      bool           `json:"read"`
-	CreatedAt int64          `json:"created_at"`
-	UpdatedAt int64          `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
-}
+var (
+	errNotChatMember  = errors.New("not a member of this chat")
+	errChatRoomClosed = errors.New("chat room is archived")
+)
 
 // RegisterChatRoutes registers chat-related routes
-func RegisterChatRoutes(router *gin.RouterGroup, db *gorm.DB) {
+func RegisterChatRoutes(router *gin.RouterGroup, db *gorm.DB, hub *realtime.Hub) {
 	chatGroup := router.Group("/chats")
 	{
 		chatGroup.GET("", getChats(db))
+		chatGroup.POST("", openEventChat(db))
+		chatGroup.POST("/direct", openDirectChat(db))
 		chatGroup.GET("/:id", getChat(db))
-		chatGroup.POST("", createChat(db))
-		chatGroup.POST("/:id/messages", sendMessage(db))
-		chatGroup.PUT("/:id/messages/:messageId/read", markMessageAsRead(db))
+		chatGroup.GET("/:id/messages", getMessages(db))
+		chatGroup.POST("/:id/messages", sendMessage(db, hub))
+		chatGroup.POST("/:id/read", markChatRead(db, hub))
 	}
 }
 
-// getChats returns all chats for the current user
+// getChats returns the current user's event and direct rooms, most recently
+// active first, with each room's last message and unread count
 func getChats(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID := c.GetUint("user_id")
-		var chats []Chat
-		if err := db.Preload("User1").Preload("User2").Preload("Messages").
-			Where("user1_id = ? OR user2_id = ?", userID, userID).
-			Find(&chats).Error; err != nil {
+		userID := c.GetString("user_id")
+
+		var rooms []models.ChatRoom
+		if err := db.Preload("Event").
+			Where("(type = ? AND id IN (SELECT chat_room_id FROM chat_room_members WHERE user_id = ?))", models.ChatRoomTypeDirect, userID).
+			Or("(type = ? AND event_id IN (SELECT id FROM events WHERE host_id = ? UNION SELECT event_id FROM event_attendees WHERE user_id = ?))",
+				models.ChatRoomTypeEvent, userID, userID).
+			Order("updated_at DESC").
+			Find(&rooms).Error; err != nil {
+			log.Printf("Error fetching chats for user %s: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch chats"})
+			return
+		}
+
+		responses, err := chatRoomResponses(db, userID, rooms)
+		if err != nil {
+			log.Printf("Error building chats for user %s: %v", userID, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch chats"})
 			return
 		}
-		c.JSON(http.StatusOK, chats)
+		c.JSON(http.StatusOK, responses)
 	}
 }
 
-// getChat returns a specific chat
+// getChat returns a single room the current user belongs to
 func getChat(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID := c.GetUint("user_id")
-		chatID := c.Param("id")
-		var chat Chat
-		if err := db.Preload("User1").Preload("User2").Preload("Messages").
-			Where("id = ? AND (user1_id = ? OR user2_id = ?)", chatID, userID, userID).
-			First(&chat).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Chat not found"})
+		userID := c.GetString("user_id")
+		room, _, err := loadChatRoom(db, c.Param("id"), userID)
+		if err != nil {
+			respondChatError(c, err, "Failed to fetch chat")
 			return
 		}
-		c.JSON(http.StatusOK, chat)
+		responses, err := chatRoomResponses(db, userID, []models.ChatRoom{*room})
+		if err != nil {
+			respondChatError(c, err, "Failed to fetch chat")
+			return
+		}
+		c.JSON(http.StatusOK, responses[0])
 	}
 }
 
-// createChat creates a new chat
-func createChat(db *gorm.DB) gin.HandlerFunc {
+// openEventChat returns the group room of an event, creating it on first
+// use. Only the host and attendees may open it.
+func openEventChat(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID := c.GetUint("user_id")
-		var req struct {
-			User2ID uint `json:"user2_id" binding:"required"`
+		userID := c.GetString("user_id")
+		var req schemas.ChatRoomCreate
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		room, err := ensureEventChatRoom(db, req.EventID)
+		if err != nil {
+			respondChatError(c, err, "Failed to open chat")
+			return
 		}
+		memberIDs, err := chatRoomMemberIDs(db, room)
+		if err != nil {
+			respondChatError(c, err, "Failed to open chat")
+			return
+		}
+		if !containsString(memberIDs, userID) {
+			respondChatError(c, errNotChatMember, "Failed to open chat")
+			return
+		}
+
+		responses, err := chatRoomResponses(db, userID, []models.ChatRoom{*room})
+		if err != nil {
+			respondChatError(c, err, "Failed to open chat")
+			return
+		}
+		c.JSON(http.StatusOK, responses[0])
+	}
+}
+
+// openDirectChat returns the direct room between the current user and
+// another user, creating it if they have not chatted before
+func openDirectChat(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		var req schemas.DirectChatCreate
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		otherID := strings.TrimSpace(req.UserID)
+		if otherID == userID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot chat with yourself"})
+			return
+		}
 
-		// Check if chat already exists
-		var existingChat Chat
-		if err := db.Where("(user1_id = ? AND user2_id = ?) OR (user1_id = ? AND user2_id = ?)",
-			userID, req.User2ID, req.User2ID, userID).First(&existingChat).Error; err == nil {
-			c.JSON(http.StatusOK, existingChat)
+		var other models.User
+		if err := db.First(&other, "id = ?", otherID).Error; err != nil || other.IsSuspended() {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 			return
 		}
 
-		chat := Chat{
-			User1ID: userID,
-			User2ID: req.User2ID,
+		ids := []string{userID, otherID}
+		sort.Strings(ids)
+		key := ids[0] + ":" + ids[1]
+
+		var room models.ChatRoom
+		err := db.Transaction(func(tx *gorm.DB) error {
+			room = models.ChatRoom{
+				Type:      models.ChatRoomTypeDirect,
+				DirectKey: &key,
+				Status:    models.ChatRoomStatusActive,
+			}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&room)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				// Another request created the room first
+				room = models.ChatRoom{}
+				return tx.First(&room, "direct_key = ?", key).Error
+			}
+			members := []models.ChatRoomMember{
+				{ChatRoomID: room.ID, UserID: userID},
+				{ChatRoomID: room.ID, UserID: otherID},
+			}
+			return tx.Create(&members).Error
+		})
+		if err != nil {
+			respondChatError(c, err, "Failed to open chat")
+			return
 		}
-		if err := db.Create(&chat).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chat"})
+
+		responses, err := chatRoomResponses(db, userID, []models.ChatRoom{room})
+		if err != nil {
+			respondChatError(c, err, "Failed to open chat")
 			return
 		}
-		c.JSON(http.StatusCreated, chat)
+		c.JSON(http.StatusOK, responses[0])
 	}
 }
 
-// sendMessage sends a message in a chat
-func sendMessage(db *gorm.DB) gin.HandlerFunc {
+// getMessages returns a room's messages newest first. Pass the returned
+// next_cursor as cursor to fetch older messages.
+func getMessages(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID := c.GetUint("user_id")
-		chatID := c.Param("id")
-		var req struct {
-			Content string `json:"content" binding:"required"`
+		userID := c.GetString("user_id")
+		room, _, err := loadChatRoom(db, c.Param("id"), userID)
+		if err != nil {
+			respondChatError(c, err, "Failed to fetch messages")
+			return
+		}
+
+		_, limit := parsePagination(c)
+		query := db.Preload("Sender").Where("chat_room_id = ?", room.ID)
+		if cursor := c.Query("cursor"); cursor != "" {
+			beforeID, err := strconv.ParseUint(cursor, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+				return
+			}
+			query = query.Where("id < ?", beforeID)
 		}
+
+		var messages []models.Message
+		if err := query.Order("id DESC").Limit(limit + 1).Find(&messages).Error; err != nil {
+			respondChatError(c, err, "Failed to fetch messages")
+			return
+		}
+
+		var nextCursor *string
+		if len(messages) > limit {
+			messages = messages[:limit]
+			next := strconv.FormatUint(uint64(messages[len(messages)-1].ID), 10)
+			nextCursor = &next
+		}
+
+		items := make([]schemas.MessageResponse, len(messages))
+		for i := range messages {
+			items[i] = toMessageResponse(&messages[i])
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"messages":    items,
+			"next_cursor": nextCursor,
+		})
+	}
+}
+
+// sendMessage posts a message to a room and pushes it to every member
+func sendMessage(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		var req schemas.MessageCreate
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		content := strings.TrimSpace(req.Content)
+		if content == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Message cannot be empty"})
+			return
+		}
 
-		// Verify chat exists and user is a participant
-		var chat Chat
-		if err := db.Where("id = ? AND (user1_id = ? OR user2_id = ?)", chatID, userID, userID).
-			First(&chat).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Chat not found"})
+		room, memberIDs, err := loadChatRoom(db, c.Param("id"), userID)
+		if err != nil {
+			respondChatError(c, err, "Failed to send message")
+			return
+		}
+		if room.Status != models.ChatRoomStatusActive {
+			respondChatError(c, errChatRoomClosed, "Failed to send message")
 			return
 		}
 
-		message := Message{
-			ChatID:   chat.ID,
-			SenderID: userID,
-			Content:  req.Content,
+		message := models.Message{
+			ChatRoomID: room.ID,
+			SenderID:   userID,
+			Content:    content,
 		}
-		if err := db.Create(&message).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&message).Error; err != nil {
+				return err
+			}
+			// Keep the room list ordered by latest activity
+			return tx.Model(&models.ChatRoom{}).Where("id = ?", room.ID).Update("updated_at", message.CreatedAt).Error
+		})
+		if err != nil {
+			respondChatError(c, err, "Failed to send message")
 			return
 		}
-		c.JSON(http.StatusCreated, message)
+		message.Sender = c.MustGet("user").(*models.User)
+
+		response := toMessageResponse(&message)
+		for _, memberID := range memberIDs {
+			hub.Publish(memberID, realtime.EventMessage, response)
+		}
+		c.JSON(http.StatusCreated, response)
 	}
 }
 
-// markMessageAsRead marks a message as read
-func markMessageAsRead(db *gorm.DB) gin.HandlerFunc {
+// markChatRead moves the current user's read position forward and tells the
+// other members. In direct rooms the other user's messages are stamped with
+// a read time.
+func markChatRead(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID := c.GetUint("user_id")
-		chatID := c.Param("id")
-		messageID := c.Param("messageId")
+		userID := c.GetString("user_id")
+		var req schemas.ChatReadCreate
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
 
-		// Verify chat exists and user is a participant
-		var chat Chat
-		if err := db.Where("id = ? AND (user1_id = ? OR user2_id = ?)", chatID, userID, userID).
-			First(&chat).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Chat not found"})
+		room, memberIDs, err := loadChatRoom(db, c.Param("id"), userID)
+		if err != nil {
+			respondChatError(c, err, "Failed to mark chat as read")
 			return
 		}
 
-		// Update message
-		if err := db.Model(&Message{}).
-			Where("id = ? AND chat_id = ?", messageID, chatID).
-			Update("read", true).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark message as read"})
+		messageID := req.MessageID
+		if messageID == 0 {
+			if err := db.Model(&models.Message{}).Where("chat_room_id = ?", room.ID).
+				Select("COALESCE(MAX(id), 0)").Scan(&messageID).Error; err != nil {
+				respondChatError(c, err, "Failed to mark chat as read")
+				return
+			}
+		}
+
+		now := time.Now()
+		err = db.Transaction(func(tx *gorm.DB) error {
+			member := models.ChatRoomMember{
+				ChatRoomID:        room.ID,
+				UserID:            userID,
+				LastReadMessageID: messageID,
+				LastReadAt:        &now,
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "chat_room_id"}, {Name: "user_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"last_read_message_id": gorm.Expr("GREATEST(chat_room_members.last_read_message_id, EXCLUDED.last_read_message_id)"),
+					"last_read_at":         now,
+				}),
+			}).Create(&member).Error; err != nil {
+				return err
+			}
+
+			if room.Type != models.ChatRoomTypeDirect {
+				return nil
+			}
+			return tx.Model(&models.Message{}).
+				Where("chat_room_id = ? AND sender_id <> ? AND id <= ? AND read_at IS NULL", room.ID, userID, messageID).
+				Update("read_at", now).Error
+		})
+		if err != nil {
+			respondChatError(c, err, "Failed to mark chat as read")
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"message": "Message marked as read"})
+
+		receipt := gin.H{
+			"chat_room_id": room.ID,
+			"user_id":      userID,
+			"message_id":   messageID,
+			"read_at":      now,
+		}
+		for _, memberID := range memberIDs {
+			hub.Publish(memberID, realtime.EventReadReceipt, receipt)
+		}
+		c.JSON(http.StatusOK, receipt)
+	}
+}
+
+// ensureEventChatRoom returns the group room of an event, creating it if
+// needed
+func ensureEventChatRoom(db *gorm.DB, eventID string) (*models.ChatRoom, error) {
+	var event models.Event
+	if err := db.First(&event, "id = ?", eventID).Error; err != nil {
+		return nil, err
+	}
+
+	room := models.ChatRoom{
+		Type:    models.ChatRoomTypeEvent,
+		EventID: &event.ID,
+		Status:  models.ChatRoomStatusActive,
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&room)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		room = models.ChatRoom{}
+		if err := db.First(&room, "event_id = ?", event.ID).Error; err != nil {
+			return nil, err
+		}
+	}
+	room.Event = &event
+	return &room, nil
+}
+
+// ArchiveEventChat is a lifecycle hook that archives an event's room when
+// the event is cancelled, so no new messages can be posted
+func ArchiveEventChat(tx *gorm.DB, change lifecycle.StatusChange) error {
+	if change.To != models.EventStatusCancelled {
+		return nil
+	}
+	return tx.Model(&models.ChatRoom{}).
+		Where("event_id = ?", change.Event.ID).
+		Update("status", models.ChatRoomStatusArchived).Error
+}
+
+// loadChatRoom loads a room with its event and returns its member IDs,
+// failing with errNotChatMember if the user is not one of them
+func loadChatRoom(db *gorm.DB, roomID string, userID string) (*models.ChatRoom, []string, error) {
+	id, err := strconv.ParseUint(roomID, 10, 64)
+	if err != nil {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+	var room models.ChatRoom
+	if err := db.Preload("Event").First(&room, "id = ?", id).Error; err != nil {
+		return nil, nil, err
+	}
+	memberIDs, err := chatRoomMemberIDs(db, &room)
+	if err != nil {
+		return nil, nil, err
 	}
+	if !containsString(memberIDs, userID) {
+		return nil, nil, errNotChatMember
+	}
+	return &room, memberIDs, nil
+}
+
+// chatRoomMemberIDs returns who may read and post in a room: the two users
+// of a direct room, or the host and attendees of an event room
+func chatRoomMemberIDs(db *gorm.DB, room *models.ChatRoom) ([]string, error) {
+	var ids []string
+	if room.Type == models.ChatRoomTypeDirect {
+		err := db.Model(&models.ChatRoomMember{}).Where("chat_room_id = ?", room.ID).Pluck("user_id", &ids).Error
+		return ids, err
+	}
+
+	if room.EventID == nil {
+		return nil, nil
+	}
+	err := db.Raw("SELECT host_id FROM events WHERE id = ? UNION SELECT user_id FROM event_attendees WHERE event_id = ?",
+		*room.EventID, *room.EventID).Scan(&ids).Error
+	return ids, err
 }
 
-// Add a helper function to convert string ID to uint
-func stringToUint(s string) uint {
-	id, _ := strconv.ParseUint(s, 10, 64)
-	return uint(id)
+// chatRoomResponses builds room responses for a user, loading each room's
+// last message, unread count and, for direct rooms, the other participant
+func chatRoomResponses(db *gorm.DB, userID string, rooms []models.ChatRoom) ([]schemas.ChatRoomResponse, error) {
+	responses := make([]schemas.ChatRoomResponse, 0, len(rooms))
+	if len(rooms) == 0 {
+		return responses, nil
+	}
+
+	roomIDs := make([]uint, len(rooms))
+	var directIDs []uint
+	for i, room := range rooms {
+		roomIDs[i] = room.ID
+		if room.Type == models.ChatRoomTypeDirect {
+			directIDs = append(directIDs, room.ID)
+		}
+	}
+
+	var lastMessages []models.Message
+	if err := db.Preload("Sender").
+		Where("id IN (SELECT MAX(id) FROM messages WHERE chat_room_id IN ? GROUP BY chat_room_id)", roomIDs).
+		Find(&lastMessages).Error; err != nil {
+		return nil, err
+	}
+	lastByRoom := make(map[uint]*models.Message, len(lastMessages))
+	for i := range lastMessages {
+		lastByRoom[lastMessages[i].ChatRoomID] = &lastMessages[i]
+	}
+
+	var unread []struct {
+		ChatRoomID uint
+		Count      int64
+	}
+	if err := db.Raw(`SELECT m.chat_room_id, COUNT(*) AS count FROM messages m
+		LEFT JOIN chat_room_members crm ON crm.chat_room_id = m.chat_room_id AND crm.user_id = ?
+		WHERE m.chat_room_id IN ? AND m.sender_id <> ? AND m.id > COALESCE(crm.last_read_message_id, 0)
+		GROUP BY m.chat_room_id`, userID, roomIDs, userID).
+		Scan(&unread).Error; err != nil {
+		return nil, err
+	}
+	unreadByRoom := make(map[uint]int64, len(unread))
+	for _, u := range unread {
+		unreadByRoom[u.ChatRoomID] = u.Count
+	}
+
+	otherByRoom := make(map[uint]*models.User)
+	if len(directIDs) > 0 {
+		var members []models.ChatRoomMember
+		if err := db.Where("chat_room_id IN ? AND user_id <> ?", directIDs, userID).Find(&members).Error; err != nil {
+			return nil, err
+		}
+		otherIDs := make([]string, len(members))
+		for i, m := range members {
+			otherIDs[i] = m.UserID
+		}
+		var users []models.User
+		if len(otherIDs) > 0 {
+			if err := db.Where("id IN ?", otherIDs).Find(&users).Error; err != nil {
+				return nil, err
+			}
+		}
+		usersByID := make(map[string]*models.User, len(users))
+		for i := range users {
+			usersByID[users[i].ID] = &users[i]
+		}
+		for _, m := range members {
+			otherByRoom[m.ChatRoomID] = usersByID[m.UserID]
+		}
+	}
+
+	for _, room := range rooms {
+		response := schemas.ChatRoomResponse{
+			ID:          room.ID,
+			Type:        string(room.Type),
+			EventID:     room.EventID,
+			Status:      room.Status,
+			UnreadCount: unreadByRoom[room.ID],
+			CreatedAt:   room.CreatedAt,
+			UpdatedAt:   room.UpdatedAt,
+		}
+		if room.Event != nil {
+			response.Event = &schemas.ChatRoomEvent{
+				ID:     room.Event.ID,
+				Title:  room.Event.Title,
+				Date:   room.Event.Date,
+				Status: string(room.Event.Status),
+				HostID: room.Event.HostID,
+			}
+		}
+		if other := otherByRoom[room.ID]; other != nil {
+			participant := toUserSummary(other)
+			response.Participant = &participant
+		}
+		if last := lastByRoom[room.ID]; last != nil {
+			message := toMessageResponse(last)
+			response.LastMessage = &message
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// toUserSummary converts a user to the small public profile
+func toUserSummary(user *models.User) schemas.UserSummary {
+	if user == nil {
+		return schemas.UserSummary{}
+	}
+	return schemas.UserSummary{
+		ID:        user.ID,
+		Name:      user.Name,
+		Username:  user.Username,
+		AvatarURL: user.AvatarURL,
+	}
+}
+
+// toMessageResponse converts a message with its preloaded sender
+func toMessageResponse(message *models.Message) schemas.MessageResponse {
+	return schemas.MessageResponse{
+		ID:         message.ID,
+		ChatRoomID: message.ChatRoomID,
+		SenderID:   message.SenderID,
+		Sender:     toUserSummary(message.Sender),
+		Content:    message.Content,
+		ReadAt:     message.ReadAt,
+		CreatedAt:  message.CreatedAt,
+	}
+}
+
+// containsString reports whether s is in values
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// respondChatError maps chat errors to responses
+func respondChatError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chat not found"})
+	case errNotChatMember:
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not a member of this chat"})
+	case errChatRoomClosed:
+		c.JSON(http.StatusConflict, gin.H{"error": "This chat has been archived"})
+	default:
+		log.Printf("%s: %v", fallback, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
 }
@@ -0,0 +1,369 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/schemas"
+	"spotlight-backend-go/internal/wallet"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultBidBuckets and maxBidBuckets bound the bid distribution histogram
+	defaultBidBuckets = 10
+	maxBidBuckets     = 50
+	// defaultSeriesDays is the range of the daily bid series when none is given
+	defaultSeriesDays = 30
+	// maxSeriesDays caps the daily bid series
+	maxSeriesDays = 366
+)
+
+// RegisterDashboardRoutes registers the host dashboard routes. Every
+// endpoint only covers events hosted by the current user.
+func RegisterDashboardRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	dashboardGroup := router.Group("/dashboard")
+	{
+		dashboardGroup.GET("/events", getDashboardEvents(db))
+		dashboardGroup.GET("/events/:id", getDashboardEventStats(db))
+		dashboardGroup.GET("/bids/daily", getDashboardDailyBids(db))
+		dashboardGroup.GET("/earnings", getDashboardEarnings(db))
+	}
+}
+
+// getDashboardEvents lists the host's events, newest first, with their
+// headline numbers
+func getDashboardEvents(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		page, limit := parsePagination(c)
+
+		summaries := []schemas.HostEventSummary{}
+		if err := db.Raw(`SELECT e.id, e.title, e.date, e.status, e.auction_mode, e.capacity,
+				(SELECT COUNT(*) FROM event_attendees ea WHERE ea.event_id = e.id) AS attendee_count,
+				(SELECT COUNT(*) FROM applications a WHERE a.event_id = e.id AND a.status <> ?) AS applicant_count,
+				(SELECT COALESCE(MAX(a.bid_amount), 0) FROM applications a WHERE a.event_id = e.id AND a.status <> ?) AS highest_bid
+			FROM events e
+			WHERE e.host_id = ?
+			ORDER BY e.date DESC, e.id
+			LIMIT ? OFFSET ?`,
+			models.ApplicationStatusWithdrawn, models.ApplicationStatusWithdrawn, userID, limit, (page-1)*limit).
+			Scan(&summaries).Error; err != nil {
+			log.Printf("Error fetching dashboard events for %s: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+			return
+		}
+		for i := range summaries {
+			summaries[i].FillRate = fillRate(summaries[i].AttendeeCount, summaries[i].Capacity)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"events": summaries,
+			"page":   page,
+			"limit":  limit,
+		})
+	}
+}
+
+// getDashboardEventStats returns applicant counts, bid statistics and the
+// bid distribution for one of the host's events. Withdrawn bids are
+// counted but left out of the statistics.
+func getDashboardEventStats(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		event, ok := hostedEventForDashboard(c, db, c.Param("id"))
+		if !ok {
+			return
+		}
+
+		buckets, err := strconv.Atoi(c.DefaultQuery("buckets", strconv.Itoa(defaultBidBuckets)))
+		if err != nil || buckets < 1 || buckets > maxBidBuckets {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Buckets must be between 1 and " + strconv.Itoa(maxBidBuckets)})
+			return
+		}
+
+		stats := schemas.EventStats{
+			EventID:      event.ID,
+			Title:        event.Title,
+			Status:       string(event.Status),
+			AuctionMode:  string(event.AuctionMode),
+			Capacity:     event.Capacity,
+			Distribution: []schemas.BidBucket{},
+		}
+
+		var counts struct {
+			Pending   int64
+			Accepted  int64
+			Rejected  int64
+			Withdrawn int64
+		}
+		if err := db.Model(&models.Application{}).
+			Select("COUNT(*) FILTER (WHERE status = ?) AS pending, "+
+				"COUNT(*) FILTER (WHERE status = ?) AS accepted, "+
+				"COUNT(*) FILTER (WHERE status = ?) AS rejected, "+
+				"COUNT(*) FILTER (WHERE status = ?) AS withdrawn",
+				models.ApplicationStatusPending, models.ApplicationStatusAccepted,
+				models.ApplicationStatusRejected, models.ApplicationStatusWithdrawn).
+			Where("event_id = ?", event.ID).
+			Scan(&counts).Error; err != nil {
+			respondDashboardError(c, event.ID, err)
+			return
+		}
+		stats.PendingCount = counts.Pending
+		stats.AcceptedCount = counts.Accepted
+		stats.RejectedCount = counts.Rejected
+		stats.WithdrawnCount = counts.Withdrawn
+		stats.ApplicantCount = counts.Pending + counts.Accepted + counts.Rejected
+
+		if err := db.Model(&models.EventAttendee{}).Where("event_id = ?", event.ID).Count(&stats.AttendeeCount).Error; err != nil {
+			respondDashboardError(c, event.ID, err)
+			return
+		}
+		stats.FillRate = fillRate(stats.AttendeeCount, event.Capacity)
+
+		if err := db.Model(&models.Bid{}).Where("event_id = ?", event.ID).Count(&stats.BidRevisions).Error; err != nil {
+			respondDashboardError(c, event.ID, err)
+			return
+		}
+
+		if err := db.Raw(`SELECT COUNT(*) AS count,
+				COALESCE(MIN(bid_amount), 0) AS lowest,
+				COALESCE(MAX(bid_amount), 0) AS highest,
+				COALESCE(AVG(bid_amount), 0) AS average,
+				COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY bid_amount), 0) AS median,
+				COALESCE(percentile_cont(0.25) WITHIN GROUP (ORDER BY bid_amount), 0) AS p25,
+				COALESCE(percentile_cont(0.75) WITHIN GROUP (ORDER BY bid_amount), 0) AS p75,
+				COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY bid_amount), 0) AS p90
+			FROM applications
+			WHERE event_id = ? AND status <> ?`,
+			event.ID, models.ApplicationStatusWithdrawn).
+			Scan(&stats.Bids).Error; err != nil {
+			respondDashboardError(c, event.ID, err)
+			return
+		}
+		stats.Bids.Average = wallet.Round(stats.Bids.Average)
+		stats.Bids.Median = wallet.Round(stats.Bids.Median)
+		stats.Bids.P25 = wallet.Round(stats.Bids.P25)
+		stats.Bids.P75 = wallet.Round(stats.Bids.P75)
+		stats.Bids.P90 = wallet.Round(stats.Bids.P90)
+
+		if stats.Bids.Count > 0 {
+			distribution, err := bidDistribution(db, event.ID, stats.Bids.Lowest, stats.Bids.Highest, buckets)
+			if err != nil {
+				respondDashboardError(c, event.ID, err)
+				return
+			}
+			stats.Distribution = distribution
+		}
+
+		c.JSON(http.StatusOK, stats)
+	}
+}
+
+// bidDistribution splits the active bids between lowest and highest into
+// equal-width buckets. Empty buckets are included so the result can be
+// charted directly.
+func bidDistribution(db *gorm.DB, eventID string, lowest float64, highest float64, buckets int) ([]schemas.BidBucket, error) {
+	if highest <= lowest {
+		buckets = 1
+	}
+	width := (highest - lowest) / float64(buckets)
+
+	distribution := make([]schemas.BidBucket, buckets)
+	for i := range distribution {
+		distribution[i].From = wallet.Round(lowest + width*float64(i))
+		distribution[i].To = wallet.Round(lowest + width*float64(i+1))
+	}
+	distribution[buckets-1].To = highest
+
+	if buckets == 1 {
+		err := db.Model(&models.Application{}).
+			Where("event_id = ? AND status <> ?", eventID, models.ApplicationStatusWithdrawn).
+			Count(&distribution[0].Count).Error
+		return distribution, err
+	}
+
+	// width_bucket puts the highest bid in bucket buckets+1, so it is folded
+	// into the last bucket
+	var rows []struct {
+		Bucket int
+		Count  int64
+	}
+	if err := db.Raw(`SELECT LEAST(width_bucket(bid_amount, ?, ?, ?), ?) AS bucket, COUNT(*) AS count
+		FROM applications
+		WHERE event_id = ? AND status <> ?
+		GROUP BY 1`,
+		lowest, highest, buckets, buckets, eventID, models.ApplicationStatusWithdrawn).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if row.Bucket >= 1 && row.Bucket <= buckets {
+			distribution[row.Bucket-1].Count = row.Count
+		}
+	}
+	return distribution, nil
+}
+
+// getDashboardDailyBids returns the number of bids placed per day, counting
+// every revision, across the host's events or for one event given by
+// event_id. from and to are dates and default to the last 30 days.
+func getDashboardDailyBids(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+
+		filter := "b.event_id IN (SELECT id FROM events WHERE host_id = ?)"
+		filterArg := userID
+		if eventID := c.Query("event_id"); eventID != "" {
+			event, ok := hostedEventForDashboard(c, db, eventID)
+			if !ok {
+				return
+			}
+			filter = "b.event_id = ?"
+			filterArg = event.ID
+		}
+
+		to := time.Now().UTC().Truncate(24 * time.Hour)
+		if value := c.Query("to"); value != "" {
+			t, _, err := parseDateParam(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date. Use YYYY-MM-DD or ISO 8601"})
+				return
+			}
+			to = t.UTC().Truncate(24 * time.Hour)
+		}
+		from := to.AddDate(0, 0, -(defaultSeriesDays - 1))
+		if value := c.Query("from"); value != "" {
+			t, _, err := parseDateParam(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date. Use YYYY-MM-DD or ISO 8601"})
+				return
+			}
+			from = t.UTC().Truncate(24 * time.Hour)
+		}
+		if from.After(to) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "From must not be after to"})
+			return
+		}
+		if to.Sub(from) >= maxSeriesDays*24*time.Hour {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Date range must not exceed " + strconv.Itoa(maxSeriesDays) + " days"})
+			return
+		}
+
+		series := []schemas.DailyBids{}
+		if err := db.Raw(`SELECT d.day,
+				COUNT(b.id) AS bids,
+				COUNT(DISTINCT b.user_id) AS bidders,
+				COALESCE(MAX(b.amount), 0) AS highest_bid
+			FROM generate_series(?::date, ?::date, interval '1 day') AS d(day)
+			LEFT JOIN bids b ON b.created_at >= d.day AND b.created_at < d.day + interval '1 day' AND `+filter+`
+			GROUP BY d.day
+			ORDER BY d.day`,
+			from.Format("2006-01-02"), to.Format("2006-01-02"), filterArg).
+			Scan(&series).Error; err != nil {
+			log.Printf("Error fetching daily bids for %s: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bid series"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"from":   from.Format("2006-01-02"),
+			"to":     to.Format("2006-01-02"),
+			"series": series,
+		})
+	}
+}
+
+// getDashboardEarnings totals what the host earned per event from the
+// ledger: payments received, refunds paid back and the net. from and to
+// optionally limit it to transactions in that period.
+func getDashboardEarnings(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+
+		query := db.Table("ledger_entries AS le").
+			Joins("JOIN transactions t ON t.id = le.transaction_id").
+			Joins("LEFT JOIN events e ON e.id = t.related_event_id").
+			Where("le.account = ? AND COALESCE(t.related_event_id, '') <> ''", wallet.WalletAccount(userID))
+
+		if value := c.Query("from"); value != "" {
+			t, _, err := parseDateParam(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date. Use YYYY-MM-DD or ISO 8601"})
+				return
+			}
+			query = query.Where("t.created_at >= ?", t)
+		}
+		if value := c.Query("to"); value != "" {
+			t, dateOnly, err := parseDateParam(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date. Use YYYY-MM-DD or ISO 8601"})
+				return
+			}
+			if dateOnly {
+				// Include the whole day
+				t = t.AddDate(0, 0, 1)
+				query = query.Where("t.created_at < ?", t)
+			} else {
+				query = query.Where("t.created_at <= ?", t)
+			}
+		}
+
+		summary := schemas.EarningsSummary{Events: []schemas.EventEarnings{}}
+		if err := query.
+			Select("t.related_event_id AS event_id, COALESCE(e.title, '') AS title, "+
+				"COALESCE(SUM(le.amount) FILTER (WHERE le.amount > 0), 0) AS gross, "+
+				"COALESCE(-SUM(le.amount) FILTER (WHERE le.amount < 0), 0) AS refunded, "+
+				"COALESCE(SUM(le.amount), 0) AS net, "+
+				"COUNT(DISTINCT t.id) FILTER (WHERE t.type = ?) AS payments",
+				models.TransactionTypePaymentReceived).
+			Group("t.related_event_id, e.title").
+			Order("net DESC, t.related_event_id").
+			Scan(&summary.Events).Error; err != nil {
+			log.Printf("Error fetching earnings for %s: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch earnings"})
+			return
+		}
+		for _, event := range summary.Events {
+			summary.Gross += event.Gross
+			summary.Refunded += event.Refunded
+			summary.Net += event.Net
+		}
+		summary.Gross = wallet.Round(summary.Gross)
+		summary.Refunded = wallet.Round(summary.Refunded)
+		summary.Net = wallet.Round(summary.Net)
+
+		c.JSON(http.StatusOK, summary)
+	}
+}
+
+// hostedEventForDashboard loads an event and checks that the current user
+// hosts it, writing the error response if not
+func hostedEventForDashboard(c *gin.Context, db *gorm.DB, eventID string) (*models.Event, bool) {
+	var event models.Event
+	if err := db.First(&event, "id = ?", eventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return nil, false
+	}
+	if event.HostID != c.GetString("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can view this event's dashboard"})
+		return nil, false
+	}
+	return &event, true
+}
+
+// respondDashboardError logs a failed stats query for an event
+func respondDashboardError(c *gin.Context, eventID string, err error) {
+	log.Printf("Error fetching dashboard stats for event %s: %v", eventID, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event stats"})
+}
+
+// fillRate is the share of capacity taken by attendees
+func fillRate(attendees int64, capacity int) float64 {
+	if capacity <= 0 {
+		return 0
+	}
+	return float64(attendees) / float64(capacity)
+}
@@ -1,50 +1,284 @@
 package api
 
 import (
+	"errors"
+	"log"
 	"net/http"
+	"spotlight-backend-go/internal/lifecycle"
 	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/notifications"
+	"spotlight-backend-go/internal/realtime"
 	"spotlight-backend-go/internal/schemas"
+	"spotlight-backend-go/internal/wallet"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // RegisterEventRoutes registers event-related routes
-func RegisterEventRoutes(router *gin.RouterGroup, db *gorm.DB) {
+func RegisterEventRoutes(router *gin.RouterGroup, db *gorm.DB, hub *realtime.Hub, engine *lifecycle.Engine) {
 	eventGroup := router.Group("/events")
 	{
 		eventGroup.GET("", getEvents(db))
 		eventGroup.GET("/:id", getEvent(db))
 		eventGroup.POST("", createEvent(db))
-		eventGroup.PUT("/:id", updateEvent(db))
+		eventGroup.PUT("/:id", updateEvent(db, hub, engine))
 		eventGroup.DELETE("/:id", deleteEvent(db))
-		eventGroup.POST("/:id/attend", attendEvent(db))
-		eventGroup.POST("/:id/unattend", unattendEvent(db))
-		eventGroup.POST("/:id/bid", placeBid(db))
+		eventGroup.POST("/:id/cancel", cancelEvent(db, hub, engine))
+		eventGroup.POST("/:id/bid", placeBid(db, hub))
+		eventGroup.GET("/:id/bid", getMyBid(db))
+		eventGroup.PUT("/:id/bid", raiseBid(db, hub))
+		eventGroup.DELETE("/:id/bid", withdrawBid(db, hub))
 	}
 }
 
-// getEvents returns all events
+// eventSorts maps the sort query parameter to the expression events are
+// ordered by and its default direction
+var eventSorts = map[string]struct {
+	expr string
+	desc bool
+}{
+	"date":       {expr: "events.date"},
+	"min_bid":    {expr: "events.min_bid"},
+	"popularity": {expr: "(SELECT COUNT(*) FROM applications WHERE applications.event_id = events.id)", desc: true},
+}
+
+// eventListRow is an event with the counts shown in listings
+type eventListRow struct {
+	models.Event
+	AttendeeCount    int64
+	ApplicationCount int64
+}
+
+// getEvents returns a page of events in the list projection. It filters by
+// category, status, host_id, location, q (full-text, as in /search), from/to
+// (event date) and min_bid/max_bid, and sorts by date, min_bid or
+// popularity in the given order. Pass the returned next_cursor as cursor to
+// fetch the next page.
 func getEvents(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var events []models.Event
-		if err := db.Preload("Host").Preload("Attendees").Find(&events).Error; err != nil {
+		query := db.Model(&models.Event{})
+
+		if category := strings.TrimSpace(c.Query("category")); category != "" {
+			query = query.Where("events.category = ?", category)
+		}
+		if status := c.Query("status"); status != "" {
+			query = query.Where("events.status = ?", status)
+		}
+		if hostID := c.Query("host_id"); hostID != "" {
+			query = query.Where("events.host_id = ?", hostID)
+		}
+		if location := strings.TrimSpace(c.Query("location")); location != "" {
+			query = query.Where("LOWER(events.location) LIKE ?", "%"+strings.ToLower(location)+"%")
+		}
+		if q := searchQuery(c.Query("q")); q != "" {
+			query = query.Where("events.search_vector @@ to_tsquery('simple', ?)", q)
+		}
+
+		if from := c.Query("from"); from != "" {
+			t, _, err := parseDateParam(from)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date. Use YYYY-MM-DD or ISO 8601"})
+				return
+			}
+			query = query.Where("events.date >= ?", t)
+		}
+		if to := c.Query("to"); to != "" {
+			t, dateOnly, err := parseDateParam(to)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date. Use YYYY-MM-DD or ISO 8601"})
+				return
+			}
+			if dateOnly {
+				// Include the whole day
+				query = query.Where("events.date < ?", t.AddDate(0, 0, 1))
+			} else {
+				query = query.Where("events.date <= ?", t)
+			}
+		}
+
+		for _, param := range []struct {
+			name string
+			cond string
+		}{
+			{"min_bid", "events.min_bid >= ?"},
+			{"max_bid", "events.min_bid <= ?"},
+		} {
+			value := c.Query(param.name)
+			if value == "" {
+				continue
+			}
+			amount, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param.name})
+				return
+			}
+			query = query.Where(param.cond, amount)
+		}
+
+		sortName := c.DefaultQuery("sort", "date")
+		sort, ok := eventSorts[sortName]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Sort must be one of date, min_bid, popularity"})
+			return
+		}
+		desc := sort.desc
+		switch c.Query("order") {
+		case "":
+		case "asc":
+			desc = false
+		case "desc":
+			desc = true
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Order must be asc or desc"})
+			return
+		}
+		direction, compare := "ASC", ">"
+		if desc {
+			direction, compare = "DESC", "<"
+		}
+
+		if cursor := c.Query("cursor"); cursor != "" {
+			key, id, err := decodeEventCursor(cursor, sortName)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+				return
+			}
+			query = query.Where("("+sort.expr+", events.id) "+compare+" (?, ?)", key, id)
+		}
+
+		_, limit := parsePagination(c)
+		var rows []eventListRow
+		if err := query.
+			Select("events.*, " +
+				"(SELECT COUNT(*) FROM event_attendees WHERE event_attendees.event_id = events.id) AS attendee_count, " +
+				"(SELECT COUNT(*) FROM applications WHERE applications.event_id = events.id) AS application_count").
+			Order(sort.expr + " " + direction + ", events.id " + direction).
+			Limit(limit + 1).
+			Scan(&rows).Error; err != nil {
+			log.Printf("Error listing events: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+			return
+		}
+
+		var nextCursor *string
+		if len(rows) > limit {
+			rows = rows[:limit]
+			next := encodeEventCursor(sortName, &rows[len(rows)-1])
+			nextCursor = &next
+		}
+
+		items, err := eventListItems(db, rows)
+		if err != nil {
+			log.Printf("Error loading event hosts: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
 			return
 		}
-		c.JSON(http.StatusOK, events)
+		c.JSON(http.StatusOK, gin.H{
+			"events":      items,
+			"next_cursor": nextCursor,
+		})
 	}
 }
 
+// eventListItems converts rows to the list projection, loading all hosts
+// in one query
+func eventListItems(db *gorm.DB, rows []eventListRow) ([]schemas.EventListItem, error) {
+	hostIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		hostIDs = append(hostIDs, row.HostID)
+	}
+	hosts := make(map[string]*models.User, len(hostIDs))
+	if len(hostIDs) > 0 {
+		var users []models.User
+		if err := db.Where("id IN ?", hostIDs).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		for i := range users {
+			hosts[users[i].ID] = &users[i]
+		}
+	}
+
+	items := make([]schemas.EventListItem, len(rows))
+	for i, row := range rows {
+		items[i] = schemas.EventListItem{
+			ID:               row.ID,
+			Title:            row.Title,
+			Category:         row.Category,
+			Date:             row.Date,
+			Location:         row.Location,
+			Images:           row.Images,
+			MinBid:           row.MinBid,
+			Capacity:         row.Capacity,
+			BidDeadline:      row.BidDeadline,
+			Status:           string(row.Status),
+			AuctionMode:      string(row.AuctionMode),
+			CurrentBid:       row.CurrentBid,
+			HostID:           row.HostID,
+			AttendeeCount:    row.AttendeeCount,
+			ApplicationCount: row.ApplicationCount,
+		}
+		if host := hosts[row.HostID]; host != nil {
+			summary := toUserSummary(host)
+			items[i].Host = &summary
+		}
+	}
+	return items, nil
+}
+
+// encodeEventCursor builds the cursor after row for the given sort. The sort
+// name is part of the cursor so it cannot be replayed against another sort.
+func encodeEventCursor(sortName string, row *eventListRow) string {
+	var key string
+	switch sortName {
+	case "min_bid":
+		key = strconv.FormatFloat(row.MinBid, 'f', -1, 64)
+	case "popularity":
+		key = strconv.FormatInt(row.ApplicationCount, 10)
+	default:
+		key = row.Date.UTC().Format(time.RFC3339Nano)
+	}
+	return encodeCursor(sortName+":"+key, row.ID)
+}
+
+// decodeEventCursor parses a cursor produced by encodeEventCursor into the
+// typed sort key and event ID
+func decodeEventCursor(cursor string, sortName string) (interface{}, string, error) {
+	raw, id, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	name, key, ok := strings.Cut(raw, ":")
+	if !ok || name != sortName {
+		return nil, "", errors.New("cursor does not match sort")
+	}
+
+	var value interface{}
+	switch sortName {
+	case "min_bid":
+		value, err = strconv.ParseFloat(key, 64)
+	case "popularity":
+		value, err = strconv.ParseInt(key, 10, 64)
+	default:
+		value, err = time.Parse(time.RFC3339Nano, key)
+	}
+	if err != nil {
+		return nil, "", errors.New("invalid cursor")
+	}
+	return value, id, nil
+}
+
 // getEvent returns a specific event
 func getEvent(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		var event models.Event
-		if err := db.Preload("Host").Preload("Attendees").Where("id = ?", id).First(&event).Error; err != nil {
+		if err := db.Preload("Host").Preload("Winner").Preload("Attendees").Where("id = ?", id).First(&event).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 			return
 		}
@@ -79,34 +313,54 @@ func createEvent(db *gorm.DB) gin.HandlerFunc {
 			userID = "1" // Default to a known influencer for local/dev
 		}
 
+		auctionMode := models.AuctionModeSealed
+		if req.AuctionMode != "" {
+			auctionMode = models.AuctionMode(req.AuctionMode)
+		}
+		// Only ascending auctions use an increment
+		var minIncrement float64
+		if auctionMode == models.AuctionModeAscending {
+			minIncrement = req.MinIncrement
+			if minIncrement == 0 {
+				minIncrement = defaultMinIncrement
+			}
+		}
+
 		// Generate a new UUID for the event
 		eventID := uuid.New().String()
 
 		event := models.Event{
-			ID:          eventID,
-			Title:       req.Title,
-			Description: req.Description,
-			Date:        date,
-			Location:    req.Location,
-			HostID:      userID,
-			Category:    req.Category,
-			Images:      ToJSON(req.Images),
-			MinBid:      req.MinBid,
-			Capacity:    req.Capacity,
-			BidDeadline: bidDeadline,
-			Status:      models.EventStatusUpcoming,
+			ID:           eventID,
+			Title:        req.Title,
+			Description:  req.Description,
+			Date:         date,
+			Location:     req.Location,
+			HostID:       userID,
+			Category:     req.Category,
+			Images:       imagesToJSON(req.Images),
+			MinBid:       req.MinBid,
+			Capacity:     req.Capacity,
+			BidDeadline:  bidDeadline,
+			Status:       models.EventStatusUpcoming,
+			AuctionMode:  auctionMode,
+			MinIncrement: minIncrement,
 		}
 
 		if err := db.Create(&event).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event"})
 			return
 		}
+
+		// Rooms are also created on demand, so a failure here is not fatal
+		if _, err := ensureEventChatRoom(db, event.ID); err != nil {
+			log.Printf("Error creating chat room for event %s: %v", event.ID, err)
+		}
 		c.JSON(http.StatusCreated, event)
 	}
 }
 
 // updateEvent updates an existing event
-func updateEvent(db *gorm.DB) gin.HandlerFunc {
+func updateEvent(db *gorm.DB, hub *realtime.Hub, engine *lifecycle.Engine) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		var req schemas.EventUpdate
@@ -115,9 +369,23 @@ func updateEvent(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		var nextStatus models.EventStatus
+		if req.Status != nil {
+			nextStatus = models.EventStatus(*req.Status)
+			if !nextStatus.Valid() {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Status must be one of upcoming, ongoing, past, cancelled"})
+				return
+			}
+			// Cancelling refunds bids, which needs the dedicated endpoint
+			if nextStatus == models.EventStatusCancelled {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Use POST /events/:id/cancel to cancel an event"})
+				return
+			}
+		}
+
 		userID := c.GetString("user_id")
 		var event models.Event
-		if err := db.First(&event, id).Error; err != nil {
+		if err := db.First(&event, "id = ?", id).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 			return
 		}
@@ -127,6 +395,10 @@ func updateEvent(db *gorm.DB) gin.HandlerFunc {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can update this event"})
 			return
 		}
+		if nextStatus != "" && nextStatus != event.Status && !event.Status.CanTransitionTo(nextStatus) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot change event status from " + string(event.Status) + " to " + string(nextStatus)})
+			return
+		}
 
 		// Update fields
 		if req.Title != nil {
@@ -145,13 +417,26 @@ func updateEvent(db *gorm.DB) gin.HandlerFunc {
 			event.MinBid = *req.MinBid
 		}
 		if req.Images != nil {
-			event.Images = ToJSON(*req.Images)
-		}
-		if req.Status != nil {
-			event.Status = models.EventStatus(*req.Status)
-		}
-
-		if err := db.Save(&event).Error; err != nil {
+			event.Images = imagesToJSON(*req.Images)
+		}
+
+		err := hub.Transaction(db, func(tx *gorm.DB) error {
+			// Status is only changed through the lifecycle engine, and the
+			// auction fields only by bidding
+			if err := tx.Omit("status", "bidding_closed_at", "bid_deadline", "current_bid", "winner_id").Save(&event).Error; err != nil {
+				return err
+			}
+			if nextStatus == "" || nextStatus == event.Status {
+				return nil
+			}
+			return engine.Transition(tx, &event, nextStatus)
+		})
+		if err != nil {
+			if err == lifecycle.ErrInvalidTransition {
+				c.JSON(http.StatusConflict, gin.H{"error": "Event status changed, please retry"})
+				return
+			}
+			log.Printf("Error updating event %s: %v", event.ID, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
 			return
 		}
@@ -159,152 +444,193 @@ func updateEvent(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-// deleteEvent deletes an event
+// errEventHasApplications is returned when deleting an event that has
+// received bids; such events are cancelled instead so escrow is refunded
+var errEventHasApplications = errors.New("event has applications")
+
+// deleteEvent deletes an event that has never received a bid. Events with
+// applications may hold escrow and must be cancelled instead.
 func deleteEvent(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		userID := c.GetString("user_id")
 
-		var event models.Event
-		if err := db.First(&event, id).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
-			return
-		}
-
-		// Check if user is the host
-		if event.HostID != userID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can delete this event"})
-			return
-		}
-
-		if err := db.Delete(&event).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Bids lock the event row too, so none can arrive after the count
+			var event models.Event
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, "id = ?", id).Error; err != nil {
+				return err
+			}
+			if event.HostID != userID {
+				return errNotEventHost
+			}
+
+			var applicationCount int64
+			if err := tx.Model(&models.Application{}).Where("event_id = ?", event.ID).Count(&applicationCount).Error; err != nil {
+				return err
+			}
+			if applicationCount > 0 {
+				return errEventHasApplications
+			}
+			return tx.Delete(&event).Error
+		})
+		if err != nil {
+			switch err {
+			case gorm.ErrRecordNotFound:
+				c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			case errNotEventHost:
+				c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can delete this event"})
+			case errEventHasApplications:
+				c.JSON(http.StatusConflict, gin.H{"error": "Events with applications cannot be deleted; cancel the event instead"})
+			default:
+				log.Printf("Error deleting event %s: %v", id, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
+			}
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully"})
 	}
 }
 
-// attendEvent allows a user to attend an event
-func attendEvent(db *gorm.DB) gin.HandlerFunc {
+// cancelEvent cancels an event on behalf of its host or an admin. The event
+// is kept; see cancelEventTx for what happens to its bids.
+func cancelEvent(db *gorm.DB, hub *realtime.Hub, engine *lifecycle.Engine) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
-		userID := c.GetString("user_id")
-
-		var event models.Event
-		if err := db.First(&event, id).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
-			return
-		}
+		user := c.MustGet("user").(*models.User)
 
-		// Check if event is full
-		var attendeeCount int64
-		db.Model(&models.EventAttendee{}).Where("event_id = ?", event.ID).Count(&attendeeCount)
-		if attendeeCount >= int64(event.Capacity) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Event is full"})
-			return
-		}
-
-		// Add attendee
-		attendee := models.EventAttendee{
-			EventID: event.ID,
-			UserID:  userID,
-		}
-		if err := db.Create(&attendee).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to attend event"})
-			return
+		var req schemas.EventCancel
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 		}
-		c.JSON(http.StatusOK, gin.H{"message": "Successfully attended event"})
-	}
-}
-
-// unattendEvent allows a user to unattend an event
-func unattendEvent(db *gorm.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		id := c.Param("id")
-		userID := c.GetString("user_id")
+		reason := strings.TrimSpace(req.Reason)
 
 		var event models.Event
-		if err := db.First(&event, id).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		var result eventCancellation
+		err := hub.Transaction(db, func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, "id = ?", id).Error; err != nil {
+				return err
+			}
+			if event.HostID != user.ID && user.Role != models.RoleAdmin {
+				return errNotEventHost
+			}
+			var err error
+			result, err = cancelEventTx(tx, engine, &event, reason)
+			return err
+		})
+		if err != nil {
+			switch err {
+			case gorm.ErrRecordNotFound:
+				c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			case errNotEventHost:
+				c.JSON(http.StatusForbidden, gin.H{"error": "Only the host or an admin can cancel this event"})
+			case lifecycle.ErrInvalidTransition:
+				c.JSON(http.StatusConflict, gin.H{"error": "Cannot cancel an event that is " + string(event.Status)})
+			default:
+				log.Printf("Error cancelling event %s: %v", id, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel event"})
+			}
 			return
 		}
 
-		// Remove attendee
-		if err := db.Where("event_id = ? AND user_id = ?", event.ID, userID).
-			Delete(&models.EventAttendee{}).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unattend event"})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{"message": "Successfully unattended event"})
+		log.Printf("Event %s cancelled by %s", event.ID, user.ID)
+		c.JSON(http.StatusOK, gin.H{
+			"event":                 event,
+			"rejected_applications": result.Rejected,
+			"refunded_applications": result.Refunded,
+			"refunded_total":        wallet.Round(result.RefundedTotal),
+		})
 	}
 }
 
-// placeBid allows a user to place a bid on an event
-func placeBid(db *gorm.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		id := c.Param("id")
-		userID := strings.TrimSpace(c.GetString("user_id"))
-
-		// Check if user is authenticated
-		if userID == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-			return
-		}
-
-		var req struct {
-			Amount  float64 `json:"amount" binding:"required"`
-			Message string  `json:"message"`
-		}
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+// eventCancellation counts what cancelling an event did to its bids
+type eventCancellation struct {
+	Rejected      int
+	Refunded      int
+	RefundedTotal float64
+}
 
-		var event models.Event
-		if err := db.First(&event, id).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
-			return
-		}
+// cancelEventTx cancels an event whose row the caller has locked. Pending
+// applications are rejected with their escrow released, accepted bids are
+// refunded from the host's wallet and every applicant and attendee is
+// notified.
+func cancelEventTx(tx *gorm.DB, engine *lifecycle.Engine, event *models.Event, reason string) (eventCancellation, error) {
+	var result eventCancellation
+	if err := engine.Transition(tx, event, models.EventStatusCancelled); err != nil {
+		return result, err
+	}
 
-		// Check if bid deadline has passed
-		if event.BidDeadline.Before(time.Now()) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Bid deadline has passed"})
-			return
-		}
+	var applications []models.Application
+	if err := tx.Where("event_id = ?", event.ID).Find(&applications).Error; err != nil {
+		return result, err
+	}
 
-		// Check if bid amount is valid
-		if req.Amount < event.MinBid {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Bid amount must be greater than minimum bid"})
-			return
-		}
+	refunds := make(map[string]float64)
+	for i := range applications {
+		application := &applications[i]
+		switch application.Status {
+		case models.ApplicationStatusPending:
+			if err := tx.Model(&models.Application{}).
+				Where("id = ?", application.ID).
+				Update("status", models.ApplicationStatusRejected).Error; err != nil {
+				return result, err
+			}
+			application.Status = models.ApplicationStatusRejected
+
+			held, err := wallet.Balance(tx, wallet.EscrowAccount(application.FanID, event.ID))
+			if err != nil {
+				return result, err
+			}
+			if err := wallet.ReleaseBid(tx, application.FanID, event.ID, "Refund for cancelled event "+event.Title); err != nil {
+				return result, err
+			}
+			refunds[application.FanID] += held
+			result.Rejected++
+		case models.ApplicationStatusAccepted:
+			amount, err := wallet.RefundCapturedBid(tx, application.FanID, event.HostID, event.ID, "Refund for cancelled event "+event.Title)
+			if err != nil {
+				return result, err
+			}
+			if amount > 0 {
+				refunds[application.FanID] += amount
+				result.Refunded++
+			}
+		default:
+			continue
+		}
+		realtime.Enqueue(tx, application.FanID, realtime.EventBidUpdate, application)
+	}
 
-		// Create bid
-		bid := models.Bid{
-			ID:      uuid.New().String(),
-			EventID: id,
-			UserID:  userID,
-			Amount:  req.Amount,
+	// Notify every applicant and attendee once
+	recipients := make([]string, 0, len(applications))
+	seen := make(map[string]bool)
+	for _, application := range applications {
+		if !seen[application.FanID] {
+			seen[application.FanID] = true
+			recipients = append(recipients, application.FanID)
 		}
-		if err := db.Create(&bid).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to place bid"})
-			return
+	}
+	var attendeeIDs []string
+	if err := tx.Model(&models.EventAttendee{}).Where("event_id = ?", event.ID).Pluck("user_id", &attendeeIDs).Error; err != nil {
+		return result, err
+	}
+	for _, attendeeID := range attendeeIDs {
+		if !seen[attendeeID] {
+			seen[attendeeID] = true
+			recipients = append(recipients, attendeeID)
 		}
-
-		// Create or update application entry
-		application := models.Application{
-			ID:        uuid.New().String(),
-			EventID:   id,
-			FanID:     userID,
-			Status:    "pending",
-			Message:   req.Message,
-			BidAmount: req.Amount,
+	}
+	for _, recipientID := range recipients {
+		if recipientID == event.HostID {
+			continue
 		}
-		if err := db.Where("event_id = ? AND fan_id = ?", id, userID).Assign(application).FirstOrCreate(&application).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create application"})
-			return
+		result.RefundedTotal += refunds[recipientID]
+		if err := notifications.EventCancelled(tx, recipientID, event, reason, wallet.Round(refunds[recipientID])); err != nil {
+			return result, err
 		}
-
-		c.JSON(http.StatusCreated, bid)
 	}
+	return result, nil
 }
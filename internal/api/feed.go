@@ -0,0 +1,158 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"spotlight-backend-go/internal/feed"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/schemas"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// feedCandidateLimit caps how many upcoming events, soonest first, are
+// ranked for a feed
+const feedCandidateLimit = 500
+
+// RegisterFeedRoutes registers the personalized feed route
+func RegisterFeedRoutes(router *gin.RouterGroup, db *gorm.DB, scorer feed.Scorer) {
+	router.GET("/feed", getFeed(db, scorer))
+}
+
+// getFeed ranks upcoming events for the current user. The cursor pins the
+// time the first page was ranked at, so later pages rank the same events
+// the same way and nothing is skipped or repeated.
+func getFeed(db *gorm.DB, scorer feed.Scorer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := c.MustGet("user").(*models.User)
+		_, limit := parsePagination(c)
+
+		asOf := time.Now().UTC()
+		var afterScore float64
+		var afterID string
+		if cursor := c.Query("cursor"); cursor != "" {
+			var err error
+			asOf, afterScore, afterID, err = decodeFeedCursor(cursor)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+				return
+			}
+		}
+
+		var rows []eventListRow
+		if err := db.Model(&models.Event{}).
+			Select("events.*, "+
+				"(SELECT COUNT(*) FROM event_attendees WHERE event_attendees.event_id = events.id) AS attendee_count, "+
+				"(SELECT COUNT(*) FROM applications WHERE applications.event_id = events.id) AS application_count").
+			Where("events.status = ? AND events.date > ? AND events.created_at <= ? AND events.host_id <> ?",
+				models.EventStatusUpcoming, asOf, asOf, user.ID).
+			Order("events.date ASC, events.id ASC").
+			Limit(feedCandidateLimit).
+			Scan(&rows).Error; err != nil {
+			log.Printf("Error loading feed candidates for %s: %v", user.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build feed"})
+			return
+		}
+
+		var followedHosts []string
+		if err := db.Model(&models.Follow{}).Where("follower_id = ?", user.ID).Pluck("following_id", &followedHosts).Error; err != nil {
+			log.Printf("Error loading follows for %s: %v", user.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build feed"})
+			return
+		}
+
+		candidates := make([]feed.Candidate, len(rows))
+		for i := range rows {
+			candidates[i] = feed.Candidate{
+				Event:            &rows[i].Event,
+				AttendeeCount:    rows[i].AttendeeCount,
+				ApplicationCount: rows[i].ApplicationCount,
+			}
+		}
+		ranked := feed.Rank(scorer, feed.NewViewer(user, followedHosts), candidates, asOf)
+
+		start := 0
+		if afterID != "" {
+			for start < len(ranked) && !ranked[start].After(afterScore, afterID) {
+				start++
+			}
+		}
+		end := start + limit
+		if end > len(ranked) {
+			end = len(ranked)
+		}
+		page := ranked[start:end]
+
+		var nextCursor *string
+		if end < len(ranked) && len(page) > 0 {
+			last := page[len(page)-1]
+			next := encodeFeedCursor(asOf, last.Value, last.Event.ID)
+			nextCursor = &next
+		}
+
+		pageRows := make([]eventListRow, len(page))
+		for i, r := range page {
+			pageRows[i] = eventListRow{
+				Event:            *r.Event,
+				AttendeeCount:    r.AttendeeCount,
+				ApplicationCount: r.ApplicationCount,
+			}
+		}
+		listItems, err := eventListItems(db, pageRows)
+		if err != nil {
+			log.Printf("Error loading event hosts: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build feed"})
+			return
+		}
+
+		items := make([]schemas.FeedItem, len(page))
+		for i, r := range page {
+			reasons := r.Reasons
+			if reasons == nil {
+				reasons = []string{}
+			}
+			items[i] = schemas.FeedItem{
+				EventListItem: listItems[i],
+				Score:         r.Value,
+				Reasons:       reasons,
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"events":      items,
+			"next_cursor": nextCursor,
+		})
+	}
+}
+
+// encodeFeedCursor builds the cursor after the event with the given score
+// in a feed ranked at asOf
+func encodeFeedCursor(asOf time.Time, score float64, eventID string) string {
+	key := strconv.FormatInt(asOf.UnixNano(), 10) + ":" + strconv.FormatFloat(score, 'g', -1, 64)
+	return encodeCursor(key, eventID)
+}
+
+// decodeFeedCursor parses a cursor produced by encodeFeedCursor
+func decodeFeedCursor(cursor string) (time.Time, float64, string, error) {
+	key, id, err := decodeCursor(cursor)
+	if err != nil {
+		return time.Time{}, 0, "", err
+	}
+	nanos, scoreText, ok := strings.Cut(key, ":")
+	if !ok {
+		return time.Time{}, 0, "", errors.New("invalid cursor")
+	}
+	asOf, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, "", errors.New("invalid cursor")
+	}
+	score, err := strconv.ParseFloat(scoreText, 64)
+	if err != nil {
+		return time.Time{}, 0, "", errors.New("invalid cursor")
+	}
+	return time.Unix(0, asOf).UTC(), score, id, nil
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/notifications"
+	"spotlight-backend-go/internal/realtime"
+	"spotlight-backend-go/internal/schemas"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errFollowSelf       = errors.New("cannot follow yourself")
+	errNotFollowable    = errors.New("user cannot be followed")
+	errAlreadyFollowing = errors.New("already following")
+	errNotFollowing     = errors.New("not following")
+)
+
+// RegisterFollowRoutes registers follow routes under /users
+func RegisterFollowRoutes(router *gin.RouterGroup, db *gorm.DB, hub *realtime.Hub) {
+	users := router.Group("/users")
+	{
+		users.POST("/:id/follow", followUser(db, hub))
+		users.DELETE("/:id/follow", unfollowUser(db))
+		users.GET("/:id/followers", getFollowers(db))
+		users.GET("/:id/following", getFollowing(db))
+	}
+}
+
+// followUser makes the current user follow an influencer and notifies them
+func followUser(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		follower := c.MustGet("user").(*models.User)
+		targetID := c.Param("id")
+
+		var target models.User
+		err := hub.Transaction(db, func(tx *gorm.DB) error {
+			if targetID == follower.ID {
+				return errFollowSelf
+			}
+			if err := tx.First(&target, "id = ?", targetID).Error; err != nil {
+				return err
+			}
+			if target.Role != models.RoleInfluencer || target.IsSuspended() {
+				return errNotFollowable
+			}
+
+			follow := models.Follow{FollowerID: follower.ID, FollowingID: target.ID, CreatedAt: time.Now()}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errAlreadyFollowing
+			}
+
+			if err := tx.Model(&models.User{}).
+				Where("id = ?", target.ID).
+				UpdateColumn("follower_count", gorm.Expr("COALESCE(follower_count, 0) + 1")).Error; err != nil {
+				return err
+			}
+			target.FollowerCount++
+
+			return notifications.NewFollower(tx, target.ID, follower)
+		})
+		if err != nil {
+			respondFollowError(c, err, "Failed to follow user")
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{
+			"following":      true,
+			"follower_count": target.FollowerCount,
+		})
+	}
+}
+
+// unfollowUser removes the current user's follow of another user
+func unfollowUser(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		followerID := c.GetString("user_id")
+		targetID := c.Param("id")
+
+		var followerCount int
+		err := db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Where("follower_id = ? AND following_id = ?", followerID, targetID).Delete(&models.Follow{})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errNotFollowing
+			}
+
+			if err := tx.Model(&models.User{}).
+				Where("id = ?", targetID).
+				UpdateColumn("follower_count", gorm.Expr("GREATEST(COALESCE(follower_count, 0) - 1, 0)")).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.User{}).Where("id = ?", targetID).Select("follower_count").Scan(&followerCount).Error
+		})
+		if err != nil {
+			respondFollowError(c, err, "Failed to unfollow user")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"following":      false,
+			"follower_count": followerCount,
+		})
+	}
+}
+
+// getFollowers lists the users following a user, most recent first. Pass
+// the returned next_cursor as cursor to fetch the next page.
+func getFollowers(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		listFollows(c, db, "following_id", "follower_id")
+	}
+}
+
+// getFollowing lists the users a user follows, most recent first. Pass the
+// returned next_cursor as cursor to fetch the next page.
+func getFollowing(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		listFollows(c, db, "follower_id", "following_id")
+	}
+}
+
+// listFollows pages through the follows whose matchColumn is the user in
+// the path and returns the users in listColumn
+func listFollows(c *gin.Context, db *gorm.DB, matchColumn string, listColumn string) {
+	_, limit := parsePagination(c)
+
+	query := db.Table("follows").
+		Select("users.id, users.name, users.username, users.avatar_url, users.is_verified, follows.created_at AS followed_at").
+		Joins("JOIN users ON users.id = follows."+listColumn).
+		Where("follows."+matchColumn+" = ? AND users.suspended_at IS NULL", c.Param("id"))
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		createdAt, id, err := decodeTimeCursor(cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query = query.Where("(follows.created_at, follows."+listColumn+") < (?, ?)", createdAt, id)
+	}
+
+	var rows []struct {
+		schemas.UserSummary
+		IsVerified bool
+		FollowedAt time.Time
+	}
+	if err := query.
+		Order("follows.created_at DESC, follows." + listColumn + " DESC").
+		Limit(limit + 1).
+		Scan(&rows).Error; err != nil {
+		log.Printf("Error listing follows of %s: %v", c.Param("id"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	var nextCursor *string
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		encoded := encodeTimeCursor(last.FollowedAt, last.ID)
+		nextCursor = &encoded
+	}
+
+	users := make([]schemas.FollowUser, len(rows))
+	for i, row := range rows {
+		users[i] = schemas.FollowUser{
+			UserSummary: row.UserSummary,
+			IsVerified:  row.IsVerified,
+			FollowedAt:  row.FollowedAt,
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"users":       users,
+		"next_cursor": nextCursor,
+	})
+}
+
+// respondFollowError maps follow errors to responses
+func respondFollowError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errFollowSelf:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot follow yourself"})
+	case errNotFollowable:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only influencers can be followed"})
+	case errAlreadyFollowing:
+		c.JSON(http.StatusConflict, gin.H{"error": "You already follow this user"})
+	case errNotFollowing:
+		c.JSON(http.StatusNotFound, gin.H{"error": "You do not follow this user"})
+	default:
+		log.Printf("%s: %v", fallback, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
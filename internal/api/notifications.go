@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"spotlight-backend-go/internal/notifications"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RegisterNotificationRoutes registers the in-app notification inbox routes
+func RegisterNotificationRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	notificationGroup := router.Group("/notifications")
+	{
+		notificationGroup.GET("", getNotifications(db))
+		notificationGroup.GET("/unread-count", getUnreadNotificationCount(db))
+		notificationGroup.POST("/read-all", markAllNotificationsRead(db))
+		notificationGroup.POST("/:id/read", markNotificationRead(db))
+		notificationGroup.DELETE("/:id", deleteNotification(db))
+	}
+}
+
+// getNotifications returns the current user's notifications, newest first.
+// Pass the returned next_cursor as cursor to fetch the next page.
+func getNotifications(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, limit := parsePagination(c)
+
+		var after *notifications.Cursor
+		if cursor := c.Query("cursor"); cursor != "" {
+			createdAt, id, err := decodeTimeCursor(cursor)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			after = &notifications.Cursor{CreatedAt: createdAt, ID: id}
+		}
+
+		items, next, err := notifications.List(db, c.GetString("user_id"), after, limit, c.Query("unread") == "true")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+			return
+		}
+
+		var nextCursor *string
+		if next != nil {
+			encoded := encodeTimeCursor(next.CreatedAt, next.ID)
+			nextCursor = &encoded
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"notifications": items,
+			"next_cursor":   nextCursor,
+		})
+	}
+}
+
+// getUnreadNotificationCount returns the number of unread notifications
+func getUnreadNotificationCount(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		count, err := notifications.UnreadCount(db, c.GetString("user_id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"count": count})
+	}
+}
+
+// markNotificationRead marks a single notification as read
+func markNotificationRead(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := notifications.MarkRead(db, c.GetString("user_id"), c.Param("id")); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notification as read"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+	}
+}
+
+// markAllNotificationsRead marks every notification of the user as read
+func markAllNotificationsRead(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		count, err := notifications.MarkAllRead(db, c.GetString("user_id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message": "All notifications marked as read",
+			"count":   count,
+		})
+	}
+}
+
+// deleteNotification deletes a single notification
+func deleteNotification(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := notifications.Delete(db, c.GetString("user_id"), c.Param("id")); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Notification deleted"})
+	}
+}
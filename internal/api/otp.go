@@ -0,0 +1,291 @@
+package api
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/sms"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	otpLength         = 6
+	otpTTL            = 5 * time.Minute
+	otpMaxAttempts    = 5
+	otpResendCooldown = 60 * time.Second
+	otpMaxPerHour     = 5
+	// signupTokenTTL is how long a verified number can be used to register
+	signupTokenTTL = 15 * time.Minute
+)
+
+// signupTokenPurpose marks signup tokens so they can't pass as access
+// tokens or the other way round
+const signupTokenPurpose = "signup"
+
+var errInvalidSignupToken = errors.New("invalid signup token")
+
+// requestOTP sends a one-time login code to the given mobile number. The
+// response is the same whether or not the number belongs to a user.
+func requestOTP(db *gorm.DB, sender sms.SMSSender) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			MobileNumber string `json:"mobile_number" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Mobile number is required"})
+			return
+		}
+		mobileNumber := strings.TrimSpace(req.MobileNumber)
+
+		// Enforce resend cooldown
+		var latest models.OTPCode
+		err := db.Where("mobile_number = ?", mobileNumber).Order("created_at DESC").First(&latest).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if err == nil {
+			if wait := otpResendCooldown - time.Since(latest.CreatedAt); wait > 0 {
+				c.JSON(http.StatusTooManyRequests, gin.H{
+					"error":       "Please wait before requesting another code",
+					"retry_after": int(wait.Seconds()) + 1,
+				})
+				return
+			}
+		}
+
+		// Enforce hourly limit per number
+		var sentLastHour int64
+		if err := db.Model(&models.OTPCode{}).
+			Where("mobile_number = ? AND created_at > ?", mobileNumber, time.Now().Add(-time.Hour)).
+			Count(&sentLastHour).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if sentLastHour >= otpMaxPerHour {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many codes requested, try again later"})
+			return
+		}
+
+		code, err := generateOTP()
+		if err != nil {
+			log.Printf("Error generating OTP: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate code"})
+			return
+		}
+		codeHash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate code"})
+			return
+		}
+
+		now := time.Now()
+		otp := models.OTPCode{
+			ID:           uuid.New().String(),
+			MobileNumber: mobileNumber,
+			CodeHash:     string(codeHash),
+			ExpiresAt:    now.Add(otpTTL),
+			CreatedAt:    now,
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			// Any earlier code for this number stops being valid
+			if err := tx.Model(&models.OTPCode{}).
+				Where("mobile_number = ? AND consumed_at IS NULL", mobileNumber).
+				Update("consumed_at", now).Error; err != nil {
+				return err
+			}
+			return tx.Create(&otp).Error
+		})
+		if err != nil {
+			log.Printf("Error storing OTP: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate code"})
+			return
+		}
+
+		body := fmt.Sprintf("Your Spotlight login code is %s. It expires in %d minutes.", code, int(otpTTL.Minutes()))
+		if err := sender.Send(c.Request.Context(), mobileNumber, body); err != nil {
+			log.Printf("Error sending OTP to %s: %v", mobileNumber, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send code"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":    "Code sent",
+			"expires_in": int(otpTTL.Seconds()),
+		})
+	}
+}
+
+// verifyOTP exchanges a valid one-time code for an access token. For a
+// number without an account it returns a signup token instead, which
+// register accepts as proof that the number was verified.
+func verifyOTP(db *gorm.DB, jwtSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			MobileNumber string `json:"mobile_number" binding:"required"`
+			Code         string `json:"code" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Mobile number and code are required"})
+			return
+		}
+		mobileNumber := strings.TrimSpace(req.MobileNumber)
+
+		var otp models.OTPCode
+		if err := db.
+			Where("mobile_number = ? AND consumed_at IS NULL AND expires_at > ?", mobileNumber, time.Now()).
+			Order("created_at DESC").
+			First(&otp).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired code"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		if otp.Attempts >= otpMaxAttempts {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many attempts, request a new code"})
+			return
+		}
+
+		// Count the attempt before comparing so parallel guesses are limited too
+		result := db.Model(&models.OTPCode{}).
+			Where("id = ? AND attempts < ?", otp.ID, otpMaxAttempts).
+			UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if result.RowsAffected == 0 {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many attempts, request a new code"})
+			return
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(otp.CodeHash), []byte(strings.TrimSpace(req.Code))); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":              "Invalid or expired code",
+				"attempts_remaining": otpMaxAttempts - otp.Attempts - 1,
+			})
+			return
+		}
+
+		// Consume the code; only one request can win
+		result = db.Model(&models.OTPCode{}).
+			Where("id = ? AND consumed_at IS NULL", otp.ID).
+			Update("consumed_at", time.Now())
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if result.RowsAffected == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired code"})
+			return
+		}
+
+		var user models.User
+		if err := db.Where("mobile_number = ?", mobileNumber).First(&user).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				signupToken, err := generateSignupToken(jwtSecret, mobileNumber)
+				if err != nil {
+					log.Printf("Error creating signup token: %v", err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create signup token"})
+					return
+				}
+				c.JSON(http.StatusNotFound, gin.H{
+					"error":        "User not found",
+					"message":      "Please complete your registration",
+					"action":       "signup",
+					"signup_token": signupToken,
+					"expires_in":   int(signupTokenTTL.Seconds()),
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		// Start a session and generate tokens
+		response, err := issueTokens(c, db, &user, jwtSecret)
+		if err != nil {
+			log.Printf("Error creating session: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+			return
+		}
+		response["user"] = gin.H{
+			"id":              user.ID,
+			"name":            user.Name,
+			"username":        user.Username,
+			"email":           user.Email,
+			"role":            user.Role,
+			"avatar_url":      user.AvatarURL,
+			"bio":             user.Bio,
+			"mediaGallery":    user.MediaGallery,
+			"walletBalance":   user.WalletBalance,
+			"followerCount":   user.FollowerCount,
+			"instagramHandle": user.InstagramHandle,
+			"verified":        user.Verified,
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// generateOTP returns a random numeric code of otpLength digits
+func generateOTP() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < otpLength; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", otpLength, n), nil
+}
+
+// generateSignupToken returns a short-lived token proving that the holder
+// verified mobileNumber with an OTP code
+func generateSignupToken(jwtSecret string, mobileNumber string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"purpose":       signupTokenPurpose,
+		"mobile_number": mobileNumber,
+		"exp":           now.Add(signupTokenTTL).Unix(),
+		"iat":           now.Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(jwtSecret))
+}
+
+// parseSignupToken validates a signup token and returns its mobile number
+func parseSignupToken(jwtSecret string, tokenString string) (string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errInvalidSignupToken
+		}
+		return []byte(jwtSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return "", errInvalidSignupToken
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != signupTokenPurpose {
+		return "", errInvalidSignupToken
+	}
+	mobileNumber, ok := claims["mobile_number"].(string)
+	if !ok || mobileNumber == "" {
+		return "", errInvalidSignupToken
+	}
+	return mobileNumber, nil
+}
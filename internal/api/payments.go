@@ -0,0 +1,360 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/notifications"
+	"spotlight-backend-go/internal/payments"
+	"spotlight-backend-go/internal/realtime"
+	"spotlight-backend-go/internal/wallet"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errInvalidTransition = errors.New("invalid status transition")
+	errAmountMismatch    = errors.New("amount does not match order")
+)
+
+// RegisterPaymentWebhookRoutes registers the provider webhook receiver. It is
+// authenticated by the provider's signature, not by a user token.
+func RegisterPaymentWebhookRoutes(router *gin.RouterGroup, db *gorm.DB, provider payments.PaymentProvider, hub *realtime.Hub) {
+	router.POST("/payments/webhook", paymentWebhook(db, provider, hub))
+}
+
+// createTopUp starts a wallet top-up with the payment provider
+func createTopUp(db *gorm.DB, provider payments.PaymentProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Amount float64 `json:"amount" binding:"required,gt=0"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		amount := wallet.Round(req.Amount)
+
+		orderID := uuid.New().String()
+		providerOrder, err := provider.CreateOrder(c.Request.Context(), amount, orderID)
+		if err != nil {
+			log.Printf("Error creating payment order: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to create payment order"})
+			return
+		}
+
+		order := models.PaymentOrder{
+			ID:              orderID,
+			UserID:          c.GetString("user_id"),
+			Provider:        provider.Name(),
+			ProviderOrderID: providerOrder.ID,
+			Amount:          amount,
+			Status:          models.PaymentOrderStatusCreated,
+		}
+		if err := db.Create(&order).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create payment order"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"order":          order,
+			"provider_order": providerOrder,
+		})
+	}
+}
+
+// createWithdrawal debits the wallet and asks the provider to pay out
+func createWithdrawal(db *gorm.DB, provider payments.PaymentProvider, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Amount      float64 `json:"amount" binding:"required,gt=0"`
+			Destination string  `json:"destination" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		userID := c.GetString("user_id")
+
+		withdrawal := models.Withdrawal{
+			ID:          uuid.New().String(),
+			UserID:      userID,
+			Amount:      wallet.Round(req.Amount),
+			Destination: strings.TrimSpace(req.Destination),
+			Provider:    provider.Name(),
+			Status:      models.WithdrawalStatusRequested,
+		}
+		// The withdrawal moves to processing in the transaction that debits
+		// the wallet, so it can never be cancelled and refunded once a
+		// payout may exist
+		err := db.Transaction(func(tx *gorm.DB) error {
+			txn, err := wallet.Debit(tx, userID, withdrawal.Amount, models.TransactionTypeWithdrawal, "Withdrawal to "+withdrawal.Destination)
+			if err != nil {
+				return err
+			}
+			withdrawal.TransactionID = &txn.ID
+			if err := tx.Create(&withdrawal).Error; err != nil {
+				return err
+			}
+			return transitionWithdrawal(tx, &withdrawal, models.WithdrawalStatusProcessing, nil)
+		})
+		if err != nil {
+			if err == wallet.ErrInsufficientFunds {
+				c.JSON(http.StatusPaymentRequired, gin.H{"error": "Insufficient wallet balance"})
+				return
+			}
+			log.Printf("Error creating withdrawal for user %s: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create withdrawal"})
+			return
+		}
+
+		payout, err := provider.InitiatePayout(c.Request.Context(), withdrawal.Amount, withdrawal.Destination, withdrawal.ID)
+		if err != nil {
+			log.Printf("Error initiating payout for withdrawal %s: %v", withdrawal.ID, err)
+			if err := failWithdrawal(db, hub, withdrawal.ID, "Payout could not be initiated"); err != nil {
+				log.Printf("Error refunding withdrawal %s: %v", withdrawal.ID, err)
+			}
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to initiate payout"})
+			return
+		}
+
+		if err := db.Model(&withdrawal).Update("provider_payout_id", payout.ID).Error; err != nil {
+			// The payout is under way regardless; its webhooks find the
+			// withdrawal by reference and record the payout ID then
+			log.Printf("Error recording payout %s for withdrawal %s: %v", payout.ID, withdrawal.ID, err)
+		} else {
+			withdrawal.ProviderPayoutID = &payout.ID
+		}
+
+		c.JSON(http.StatusCreated, withdrawal)
+	}
+}
+
+// getWithdrawals lists the current user's withdrawals, newest first
+func getWithdrawals(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit := parsePagination(c)
+
+		var withdrawals []models.Withdrawal
+		if err := db.Where("user_id = ?", c.GetString("user_id")).
+			Order("created_at DESC").
+			Offset((page - 1) * limit).Limit(limit).
+			Find(&withdrawals).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch withdrawals"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"withdrawals": withdrawals,
+			"page":        page,
+			"limit":       limit,
+		})
+	}
+}
+
+// cancelWithdrawal cancels a withdrawal that has not been sent to the
+// provider yet and refunds the wallet
+func cancelWithdrawal(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var withdrawal models.Withdrawal
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				First(&withdrawal, "id = ? AND user_id = ?", c.Param("id"), c.GetString("user_id")).Error; err != nil {
+				return err
+			}
+			// Only a requested withdrawal is refunded; once processing, a
+			// payout may exist and only its webhooks settle the withdrawal
+			if withdrawal.Status != models.WithdrawalStatusRequested {
+				return errInvalidTransition
+			}
+			if err := transitionWithdrawal(tx, &withdrawal, models.WithdrawalStatusCancelled, nil); err != nil {
+				return err
+			}
+			_, err := wallet.Credit(tx, withdrawal.UserID, withdrawal.Amount, models.TransactionTypeWithdrawalRefund, "Withdrawal cancelled")
+			return err
+		})
+		if err != nil {
+			switch err {
+			case gorm.ErrRecordNotFound:
+				c.JSON(http.StatusNotFound, gin.H{"error": "Withdrawal not found"})
+			case errInvalidTransition:
+				c.JSON(http.StatusConflict, gin.H{"error": "Withdrawal can no longer be cancelled"})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel withdrawal"})
+			}
+			return
+		}
+		c.JSON(http.StatusOK, withdrawal)
+	}
+}
+
+// paymentWebhook applies provider notifications. Every event is safe to
+// receive more than once: a payment ID credits the wallet at most once and
+// withdrawals only move forward through their state machine.
+func paymentWebhook(db *gorm.DB, provider payments.PaymentProvider, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read body"})
+			return
+		}
+
+		event, err := provider.VerifyWebhook(payload, c.GetHeader("X-Webhook-Signature"))
+		if err != nil {
+			log.Printf("Rejected payment webhook: %v", err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+			return
+		}
+
+		switch event.Type {
+		case payments.EventPaymentCaptured:
+			err = capturePayment(db, hub, event)
+		case payments.EventPaymentFailed:
+			err = db.Model(&models.PaymentOrder{}).
+				Where("provider_order_id = ? AND status = ?", event.OrderID, models.PaymentOrderStatusCreated).
+				Update("status", models.PaymentOrderStatusFailed).Error
+		case payments.EventPayoutProcessed:
+			err = hub.Transaction(db, func(tx *gorm.DB) error {
+				withdrawal, err := lockWithdrawalForPayout(tx, event)
+				if err != nil || withdrawal.Status == models.WithdrawalStatusCompleted {
+					return err
+				}
+				if err := transitionWithdrawal(tx, withdrawal, models.WithdrawalStatusCompleted, nil); err != nil {
+					return err
+				}
+				return notifications.Payment(tx, withdrawal.UserID, "Withdrawal completed",
+					fmt.Sprintf("Your withdrawal of %.2f has been paid out.", withdrawal.Amount))
+			})
+		case payments.EventPayoutFailed:
+			var withdrawal *models.Withdrawal
+			err = db.Transaction(func(tx *gorm.DB) error {
+				withdrawal, err = lockWithdrawalForPayout(tx, event)
+				return err
+			})
+			if err == nil && withdrawal.Status != models.WithdrawalStatusFailed {
+				err = failWithdrawal(db, hub, withdrawal.ID, event.Reason)
+			}
+		default:
+			log.Printf("Ignoring payment webhook of type %s", event.Type)
+		}
+
+		if err != nil {
+			switch err {
+			case gorm.ErrRecordNotFound:
+				c.JSON(http.StatusNotFound, gin.H{"error": "Unknown order or payout"})
+			case errInvalidTransition, errAmountMismatch:
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				log.Printf("Error handling payment webhook %s: %v", event.Type, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process webhook"})
+			}
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// capturePayment credits the wallet for a captured top-up exactly once
+func capturePayment(db *gorm.DB, hub *realtime.Hub, event *payments.WebhookEvent) error {
+	return hub.Transaction(db, func(tx *gorm.DB) error {
+		var order models.PaymentOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&order, "provider_order_id = ?", event.OrderID).Error; err != nil {
+			return err
+		}
+		if order.ProviderPaymentID != nil || order.Status == models.PaymentOrderStatusPaid {
+			// Already credited; webhook redelivery
+			return nil
+		}
+		if order.Status == models.PaymentOrderStatusFailed {
+			log.Printf("Ignoring capture %s of failed order %s", event.PaymentID, order.ID)
+			return nil
+		}
+		if wallet.Round(event.Amount) != order.Amount {
+			log.Printf("Payment %s amount %.2f does not match order %s amount %.2f", event.PaymentID, event.Amount, order.ID, order.Amount)
+			return errAmountMismatch
+		}
+
+		txn, err := wallet.Credit(tx, order.UserID, order.Amount, models.TransactionTypeTopUp, "Wallet top-up")
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&order).Updates(map[string]interface{}{
+			"status":              models.PaymentOrderStatusPaid,
+			"provider_payment_id": event.PaymentID,
+			"transaction_id":      txn.ID,
+		}).Error; err != nil {
+			return err
+		}
+		return notifications.Payment(tx, order.UserID, "Wallet topped up",
+			fmt.Sprintf("%.2f has been added to your wallet.", order.Amount))
+	})
+}
+
+// failWithdrawal marks a withdrawal failed and refunds the wallet
+func failWithdrawal(db *gorm.DB, hub *realtime.Hub, withdrawalID string, reason string) error {
+	return hub.Transaction(db, func(tx *gorm.DB) error {
+		var withdrawal models.Withdrawal
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&withdrawal, "id = ?", withdrawalID).Error; err != nil {
+			return err
+		}
+		if err := transitionWithdrawal(tx, &withdrawal, models.WithdrawalStatusFailed, map[string]interface{}{
+			"failure_reason": reason,
+		}); err != nil {
+			return err
+		}
+		if _, err := wallet.Credit(tx, withdrawal.UserID, withdrawal.Amount, models.TransactionTypeWithdrawalRefund, "Withdrawal failed"); err != nil {
+			return err
+		}
+		return notifications.Payment(tx, withdrawal.UserID, "Withdrawal failed",
+			fmt.Sprintf("Your withdrawal of %.2f failed and the amount was returned to your wallet.", withdrawal.Amount))
+	})
+}
+
+// lockWithdrawalForPayout loads the withdrawal a payout event belongs to
+// with a row lock. A withdrawal whose payout ID was not recorded is found by
+// the event's reference and gets the payout ID now.
+func lockWithdrawalForPayout(tx *gorm.DB, event *payments.WebhookEvent) (*models.Withdrawal, error) {
+	var withdrawal models.Withdrawal
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&withdrawal, "provider_payout_id = ?", event.PayoutID).Error
+	if err == nil {
+		return &withdrawal, nil
+	}
+	if err != gorm.ErrRecordNotFound || event.Reference == "" {
+		return nil, err
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&withdrawal, "id = ? AND provider_payout_id IS NULL", event.Reference).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&withdrawal).Update("provider_payout_id", event.PayoutID).Error; err != nil {
+		return nil, err
+	}
+	withdrawal.ProviderPayoutID = &event.PayoutID
+	return &withdrawal, nil
+}
+
+// transitionWithdrawal moves a withdrawal to next if the state machine allows it
+func transitionWithdrawal(tx *gorm.DB, withdrawal *models.Withdrawal, next models.WithdrawalStatus, extra map[string]interface{}) error {
+	if !withdrawal.Status.CanTransitionTo(next) {
+		return errInvalidTransition
+	}
+	updates := map[string]interface{}{"status": next}
+	for k, v := range extra {
+		updates[k] = v
+	}
+	if err := tx.Model(withdrawal).Updates(updates).Error; err != nil {
+		return err
+	}
+	withdrawal.Status = next
+	return nil
+}
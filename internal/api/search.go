@@ -0,0 +1,130 @@
+package api
+
+import (
+	"html"
+	"log"
+	"net/http"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/schemas"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxSearchTerms caps how many words of a query are matched
+	maxSearchTerms = 8
+
+	// highlightStart and highlightStop mark matches in ts_headline output.
+	// They are private-use characters so they survive HTML escaping and are
+	// swapped for <mark> tags afterwards.
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+var (
+	headlineOptions = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", HighlightAll=true`
+	snippetOptions  = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", MaxWords=30, MinWords=10, MaxFragments=2`
+)
+
+// RegisterSearchRoutes registers the full-text search route
+func RegisterSearchRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	router.GET("/search", search(db))
+}
+
+// search finds events and influencers matching q. Every word is matched as
+// a prefix so partial input works for typeahead. type limits the results to
+// events or influencers; page and limit apply to each type separately.
+func search(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tsQuery := searchQuery(c.Query("q"))
+		if tsQuery == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
+			return
+		}
+
+		searchType := c.DefaultQuery("type", "all")
+		if searchType != "all" && searchType != "events" && searchType != "influencers" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Type must be one of all, events, influencers"})
+			return
+		}
+
+		page, limit := parsePagination(c)
+		offset := (page - 1) * limit
+		response := gin.H{"query": c.Query("q")}
+
+		if searchType == "all" || searchType == "events" {
+			events := []schemas.EventSearchResult{}
+			if err := db.Raw(`SELECT e.id, e.title, e.category, e.location, e.date, e.status, e.images, e.min_bid,
+					ts_rank(e.search_vector, q) AS rank,
+					ts_headline('simple', e.title, q, ?) AS title_highlight,
+					ts_headline('simple', coalesce(e.description, ''), q, ?) AS snippet
+				FROM events e, to_tsquery('simple', ?) q
+				WHERE e.search_vector @@ q AND e.status <> ?
+				ORDER BY rank DESC, e.date ASC, e.id
+				LIMIT ? OFFSET ?`,
+				headlineOptions, snippetOptions, tsQuery, models.EventStatusCancelled, limit, offset).
+				Scan(&events).Error; err != nil {
+				log.Printf("Error searching events for %q: %v", tsQuery, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+				return
+			}
+			for i := range events {
+				events[i].TitleHighlight = renderHighlight(events[i].TitleHighlight)
+				events[i].Snippet = renderHighlight(events[i].Snippet)
+			}
+			response["events"] = events
+		}
+
+		if searchType == "all" || searchType == "influencers" {
+			influencers := []schemas.InfluencerSearchResult{}
+			if err := db.Raw(`SELECT u.id, u.name, u.username, u.avatar_url, u.instagram_handle, u.follower_count, u.is_verified,
+					ts_rank(u.search_vector, q) AS rank,
+					ts_headline('simple', u.name, q, ?) AS name_highlight,
+					ts_headline('simple', coalesce(u.bio, ''), q, ?) AS snippet
+				FROM users u, to_tsquery('simple', ?) q
+				WHERE u.search_vector @@ q AND u.role = ? AND u.suspended_at IS NULL
+				ORDER BY rank DESC, u.follower_count DESC, u.id
+				LIMIT ? OFFSET ?`,
+				headlineOptions, snippetOptions, tsQuery, models.RoleInfluencer, limit, offset).
+				Scan(&influencers).Error; err != nil {
+				log.Printf("Error searching influencers for %q: %v", tsQuery, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+				return
+			}
+			for i := range influencers {
+				influencers[i].NameHighlight = renderHighlight(influencers[i].NameHighlight)
+				influencers[i].Snippet = renderHighlight(influencers[i].Snippet)
+			}
+			response["influencers"] = influencers
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// searchQuery turns free text into a prefix tsquery such as "sun:* & party:*".
+// Only letters and digits are kept, so user input cannot inject tsquery
+// operators.
+func searchQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSearchTerms {
+		words = words[:maxSearchTerms]
+	}
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// renderHighlight HTML-escapes ts_headline output and turns the match
+// markers into <mark> tags
+func renderHighlight(text string) string {
+	text = html.EscapeString(text)
+	text = strings.ReplaceAll(text, highlightStart, "<mark>")
+	return strings.ReplaceAll(text, highlightStop, "</mark>")
+}
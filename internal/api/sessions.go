@@ -0,0 +1,299 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/realtime"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	errInvalidRefreshToken = errors.New("invalid refresh token")
+	errRefreshTokenReused  = errors.New("refresh token reused")
+)
+
+// RegisterSessionRoutes registers routes for managing the current user's
+// sessions. They must be mounted behind AuthMiddleware. Revoking a session
+// also closes its open streams on hub.
+func RegisterSessionRoutes(r *gin.RouterGroup, db *gorm.DB, hub *realtime.Hub) {
+	auth := r.Group("/auth")
+	{
+		auth.POST("/logout", logout(db, hub))
+		auth.GET("/sessions", getSessions(db))
+		auth.DELETE("/sessions", revokeOtherSessions(db, hub))
+		auth.DELETE("/sessions/:id", revokeSession(db, hub))
+	}
+}
+
+// issueTokens starts a new session for the user and returns the token pair
+// to send to the client
+func issueTokens(c *gin.Context, db *gorm.DB, user *models.User, jwtSecret string) (gin.H, error) {
+	secret, err := newRefreshSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := models.Session{
+		ID:               uuid.New().String(),
+		UserID:           strings.TrimSpace(user.ID),
+		RefreshTokenHash: hashToken(secret),
+		DeviceName:       c.GetHeader("X-Device-Name"),
+		UserAgent:        c.Request.UserAgent(),
+		IPAddress:        c.ClientIP(),
+		ExpiresAt:        now.Add(refreshTokenTTL),
+		LastUsedAt:       now,
+	}
+	if err := db.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return tokenResponse(user, &session, secret, jwtSecret), nil
+}
+
+// tokenResponse builds the token part of a login or refresh response
+func tokenResponse(user *models.User, session *models.Session, secret string, jwtSecret string) gin.H {
+	return gin.H{
+		"token":         generateToken(jwtSecret, user.ID, user.Role, session.ID),
+		"refresh_token": session.ID + "." + secret,
+		"expires_in":    int(accessTokenTTL.Seconds()),
+	}
+}
+
+// refreshToken rotates a refresh token and returns a new token pair. A
+// refresh token that was already rotated is treated as stolen and revokes
+// the session.
+func refreshToken(db *gorm.DB, jwtSecret string, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			RefreshToken string `json:"refresh_token" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Refresh token is required"})
+			return
+		}
+
+		sessionID, secret, ok := strings.Cut(strings.TrimSpace(req.RefreshToken), ".")
+		if !ok || sessionID == "" || secret == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+			return
+		}
+
+		newSecret, err := newRefreshSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+			return
+		}
+
+		var session models.Session
+		var user models.User
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", sessionID).First(&session).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return errInvalidRefreshToken
+				}
+				return err
+			}
+			if !session.Active() {
+				return errInvalidRefreshToken
+			}
+
+			if session.RefreshTokenHash != hashToken(secret) {
+				return errRefreshTokenReused
+			}
+
+			if err := tx.First(&user, "id = ?", session.UserID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return errInvalidRefreshToken
+				}
+				return err
+			}
+			if user.IsSuspended() {
+				return errInvalidRefreshToken
+			}
+
+			now := time.Now()
+			session.RefreshTokenHash = hashToken(newSecret)
+			session.LastUsedAt = now
+			session.IPAddress = c.ClientIP()
+			session.UserAgent = c.Request.UserAgent()
+			return tx.Save(&session).Error
+		})
+		if err == errRefreshTokenReused {
+			// Revoke outside the transaction so the rollback doesn't undo it
+			log.Printf("Refresh token reuse detected for session %s (user %s)", session.ID, session.UserID)
+			if err := revokeSessionTx(db, &session, "refresh_token_reuse"); err != nil {
+				log.Printf("Error revoking session %s: %v", session.ID, err)
+			}
+			hub.Disconnect(session.UserID, session.ID)
+			err = errInvalidRefreshToken
+		}
+		if err != nil {
+			if err == errInvalidRefreshToken {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+				return
+			}
+			log.Printf("Error refreshing token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+			return
+		}
+
+		c.JSON(http.StatusOK, tokenResponse(&user, &session, newSecret, jwtSecret))
+	}
+}
+
+// logout revokes the session of the current access token
+func logout(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID := c.GetString("session_id")
+		userID := c.GetString("user_id")
+
+		var session models.Session
+		if err := db.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		if err := revokeSessionTx(db, &session, "logout"); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+			return
+		}
+		hub.Disconnect(userID, session.ID)
+		c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+	}
+}
+
+// getSessions lists the current user's active sessions
+func getSessions(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		currentID := c.GetString("session_id")
+
+		var sessions []models.Session
+		if err := db.
+			Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+			Order("last_used_at DESC").
+			Find(&sessions).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+			return
+		}
+
+		response := make([]gin.H, 0, len(sessions))
+		for _, s := range sessions {
+			response = append(response, gin.H{
+				"id":           s.ID,
+				"device_name":  s.DeviceName,
+				"user_agent":   s.UserAgent,
+				"ip_address":   s.IPAddress,
+				"created_at":   s.CreatedAt,
+				"last_used_at": s.LastUsedAt,
+				"expires_at":   s.ExpiresAt,
+				"current":      s.ID == currentID,
+			})
+		}
+		c.JSON(http.StatusOK, gin.H{"sessions": response})
+	}
+}
+
+// revokeSession revokes one of the current user's sessions
+func revokeSession(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+
+		var session models.Session
+		if err := db.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&session).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		if err := revokeSessionTx(db, &session, "revoked_by_user"); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+			return
+		}
+		hub.Disconnect(userID, session.ID)
+		c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+	}
+}
+
+// revokeOtherSessions revokes every session of the current user except the
+// one making the request
+func revokeOtherSessions(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		currentID := c.GetString("session_id")
+
+		var sessionIDs []string
+		if err := db.Model(&models.Session{}).
+			Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, currentID).
+			Pluck("id", &sessionIDs).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+			return
+		}
+		if len(sessionIDs) == 0 {
+			c.JSON(http.StatusOK, gin.H{"message": "Other sessions revoked", "count": 0})
+			return
+		}
+
+		result := db.Model(&models.Session{}).
+			Where("id IN ? AND revoked_at IS NULL", sessionIDs).
+			Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"revoked_reason": "revoked_by_user",
+			})
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+			return
+		}
+		hub.Disconnect(userID, sessionIDs...)
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Other sessions revoked",
+			"count":   result.RowsAffected,
+		})
+	}
+}
+
+// revokeSessionTx marks a session as revoked using the given connection
+func revokeSessionTx(tx *gorm.DB, session *models.Session, reason string) error {
+	if session.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	session.RevokedReason = reason
+	return tx.Model(session).Updates(map[string]interface{}{
+		"revoked_at":     now,
+		"revoked_reason": reason,
+	}).Error
+}
+
+// newRefreshSecret returns a random URL-safe secret for a refresh token
+func newRefreshSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex SHA-256 of a token. Refresh secrets are random
+// enough that a fast hash is sufficient.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
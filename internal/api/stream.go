@@ -0,0 +1,203 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"spotlight-backend-go/internal/middleware"
+	"spotlight-backend-go/internal/realtime"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"gorm.io/gorm"
+)
+
+const (
+	// heartbeatInterval is how often idle connections are pinged so proxies
+	// keep them open and dead clients are noticed
+	heartbeatInterval = 25 * time.Second
+	// pongWait is how long a WebSocket client may stay silent, including
+	// not answering pings, before it is disconnected
+	pongWait = 2 * heartbeatInterval
+	// writeWait bounds a single write to a client
+	writeWait = 10 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		// Native apps do not send an Origin header
+		origin := r.Header.Get("Origin")
+		return origin == "" || middleware.IsAllowedOrigin(origin)
+	},
+}
+
+// RegisterStreamRoutes registers the real-time event stream. Clients should
+// use the WebSocket endpoint and fall back to server-sent events where
+// WebSockets are unavailable; both deliver the same events. Browsers first
+// fetch a ticket and pass it as the ticket query parameter.
+func RegisterStreamRoutes(router *gin.RouterGroup, db *gorm.DB, hub *realtime.Hub, jwtSecret string) {
+	router.POST("/stream/ticket", middleware.AuthMiddleware(db, jwtSecret), createStreamTicket(hub))
+
+	streamGroup := router.Group("/stream")
+	streamGroup.Use(middleware.StreamAuthMiddleware(db, jwtSecret, hub))
+	{
+		streamGroup.GET("/ws", streamWebSocket(hub))
+		streamGroup.GET("/sse", streamSSE(hub))
+	}
+}
+
+// createStreamTicket returns a single-use ticket for opening one stream
+// connection with the current session
+func createStreamTicket(hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticket, err := hub.IssueTicket(c.GetString("user_id"), c.GetString("session_id"))
+		if err != nil {
+			log.Printf("Error issuing stream ticket: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue stream ticket"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ticket": ticket})
+	}
+}
+
+// lastEventID reads the ID of the last event a reconnecting client saw from
+// the Last-Event-ID header or the last_event_id query parameter
+func lastEventID(c *gin.Context) uint64 {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	id, _ := strconv.ParseUint(value, 10, 64)
+	return id
+}
+
+// streamWebSocket upgrades the connection and writes each event as a JSON
+// text frame. Messages from the client are ignored.
+func streamWebSocket(hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			// The upgrader has already written an error response
+			log.Printf("WebSocket upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		sub, missed := hub.Subscribe(c.GetString("user_id"), c.GetString("session_id"), lastEventID(c))
+		defer hub.Unsubscribe(sub)
+
+		// Read in the background so pongs and close frames are processed
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			conn.SetReadDeadline(time.Now().Add(pongWait))
+			conn.SetPongHandler(func(string) error {
+				return conn.SetReadDeadline(time.Now().Add(pongWait))
+			})
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		for _, event := range missed {
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case event, ok := <-sub.C:
+				conn.SetWriteDeadline(time.Now().Add(writeWait))
+				if !ok {
+					if sub.Revoked {
+						conn.WriteMessage(websocket.CloseMessage,
+							websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "session revoked"))
+						return
+					}
+					// Dropped for falling behind; the client reconnects
+					// with its last event ID to catch up
+					conn.WriteMessage(websocket.CloseMessage,
+						websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too slow"))
+					return
+				}
+				if err := conn.WriteJSON(event); err != nil {
+					return
+				}
+			case <-ticker.C:
+				conn.SetWriteDeadline(time.Now().Add(writeWait))
+				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+					return
+				}
+			case <-closed:
+				return
+			}
+		}
+	}
+}
+
+// streamSSE streams events as text/event-stream. Browsers' EventSource
+// reconnects on its own and sends Last-Event-ID.
+func streamSSE(hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sub, missed := hub.Subscribe(c.GetString("user_id"), c.GetString("session_id"), lastEventID(c))
+		defer hub.Unsubscribe(sub)
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		// Stop nginx-style proxies from buffering the stream
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		fmt.Fprintf(c.Writer, "retry: %d\n\n", 3000)
+		for _, event := range missed {
+			if err := writeSSE(c, event); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case event, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				if err := writeSSE(c, event); err != nil {
+					return
+				}
+				c.Writer.Flush()
+			case <-ticker.C:
+				if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+					return
+				}
+				c.Writer.Flush()
+			case <-c.Request.Context().Done():
+				return
+			}
+		}
+	}
+}
+
+// writeSSE writes one event in the server-sent events wire format
+func writeSSE(c *gin.Context, event realtime.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -1,74 +1,116 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
-	"os"
 	"path/filepath"
+	"spotlight-backend-go/internal/imaging"
+	"spotlight-backend-go/internal/storage"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-func RegisterUploadRoutes(r *gin.RouterGroup) {
+// maxUploadSize is the largest image file accepted
+const maxUploadSize = 5 * 1024 * 1024
+
+func RegisterUploadRoutes(r *gin.RouterGroup, store storage.BlobStore) {
 	upload := r.Group("/upload")
 	{
-		upload.POST("", uploadImage)
+		upload.POST("", uploadImage(store))
 	}
 }
 
-func uploadImage(c *gin.Context) {
-	// Get the file from the request
-	file, err := c.FormFile("image")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No image file provided"})
-		return
-	}
+// uploadImage checks an uploaded image's real content type, strips its
+// metadata by re-encoding it and stores a thumbnail, medium and large
+// variant. The original bytes are never stored.
+func uploadImage(store storage.BlobStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get the file from the request
+		file, err := c.FormFile("image")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No image file provided"})
+			return
+		}
 
-	// Validate file type
-	ext := filepath.Ext(file.Filename)
-	if !isValidImageType(ext) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file type. Only images are allowed"})
-		return
-	}
+		// Validate file size (5MB limit)
+		if file.Size > maxUploadSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "File size too large. Maximum size is 5MB"})
+			return
+		}
 
-	// Validate file size (5MB limit)
-	if file.Size > 5*1024*1024 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File size too large. Maximum size is 5MB"})
-		return
-	}
+		src, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(src, maxUploadSize))
+		src.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+			return
+		}
 
-	// Create uploads directory if it doesn't exist
-	uploadDir := "uploads"
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create upload directory"})
-		return
-	}
+		// Validate the sniffed file type against the extension
+		contentType, err := imaging.DetectContentType(data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file type. Only JPEG, PNG and GIF images are allowed"})
+			return
+		}
+		ext := strings.ToLower(filepath.Ext(file.Filename))
+		if !imaging.MatchesExtension(contentType, ext) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("File content is %s, which does not match the %s extension", contentType, ext)})
+			return
+		}
 
-	// Generate unique filename
-	filename := fmt.Sprintf("%s%s", uuid.New().String(), ext)
-	filepath := filepath.Join(uploadDir, filename)
+		variants, err := imaging.Process(data, imaging.DefaultSpecs)
+		if err == imaging.ErrTooLarge {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Image dimensions too large. Maximum is %d pixels", imaging.MaxPixels)})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to decode image"})
+			return
+		}
 
-	// Save the file
-	if err := c.SaveUploadedFile(file, filepath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
-		return
-	}
+		// Store the variants under a common prefix
+		prefix := "images/" + uuid.New().String()
+		urls, err := storeVariants(c.Request.Context(), store, prefix, variants)
+		if err != nil {
+			log.Printf("Error storing upload %s in %s store: %v", prefix, store.Name(), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+			return
+		}
 
-	// Return the URL
-	url := fmt.Sprintf("/uploads/%s", filename)
-	log.Printf("Avatar uploaded: %s", url)
-	c.JSON(http.StatusOK, gin.H{"url": url})
+		// Return the URLs; url is the large variant for older clients
+		url := urls[imaging.VariantLarge]
+		log.Printf("Image uploaded: %s", url)
+		c.JSON(http.StatusOK, gin.H{"url": url, "key": prefix, "variants": urls})
+	}
 }
 
-func isValidImageType(ext string) bool {
-	validTypes := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-		".gif":  true,
-		".webp": true,
+// storeVariants puts each variant under prefix and returns their URLs by
+// name. Variants already stored are removed if one fails.
+func storeVariants(ctx context.Context, store storage.BlobStore, prefix string, variants []imaging.Variant) (map[string]string, error) {
+	urls := make(map[string]string, len(variants))
+	var stored []string
+	for _, variant := range variants {
+		key := prefix + "/" + variant.Name + variant.Ext
+		if err := store.Put(ctx, key, bytes.NewReader(variant.Data), int64(len(variant.Data)), variant.ContentType); err != nil {
+			for _, k := range stored {
+				if err := store.Delete(ctx, k); err != nil {
+					log.Printf("Error removing partial upload %s: %v", k, err)
+				}
+			}
+			return nil, err
+		}
+		stored = append(stored, key)
+		urls[variant.Name] = store.URL(key)
 	}
-	return validTypes[ext]
+	return urls, nil
 }
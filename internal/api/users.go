@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
-	"spotlight-backend-go/internal/database"
+	"spotlight-backend-go/internal/imaging"
 	"spotlight-backend-go/internal/models"
 	"spotlight-backend-go/internal/schemas"
 	"time"
@@ -14,264 +14,299 @@ import (
 	"gorm.io/gorm"
 )
 
-func RegisterUserRoutes(r *gin.RouterGroup) {
+func RegisterUserRoutes(r *gin.RouterGroup, db *gorm.DB) {
 	users := r.Group("/users")
 	{
-		users.GET("/me", getMe)
-		users.PUT("/me", updateMe)
-		users.GET("/:id", getUserProfile)
-		users.GET("/influencers", getAllInfluencers)
-		users.GET("/me/events", getUserEvents)
+		users.GET("/me", getMe(db))
+		users.PUT("/me", updateMe(db))
+		users.GET("/:id", getUserProfile(db))
+		users.GET("/influencers", getAllInfluencers(db))
+		users.GET("/me/events", getUserEvents(db))
 	}
 }
 
-func getMe(c *gin.Context) {
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
+func getMe(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
 
-	currentUser := user.(*models.User)
+		currentUser := user.(*models.User)
 
-	var mediaGallery []string
-	_ = json.Unmarshal(currentUser.MediaGallery, &mediaGallery)
+		var mediaGallery []schemas.ImageRef
+		_ = json.Unmarshal(currentUser.MediaGallery, &mediaGallery)
 
-	// Get hosted events for influencers
-	var hostedEvents []models.Event
-	if currentUser.Role == models.RoleInfluencer {
-		database.DB.Model(currentUser).Association("HostedEvents").Find(&hostedEvents)
-	}
+		// Get hosted events for influencers
+		var hostedEvents []models.Event
+		if currentUser.Role == models.RoleInfluencer {
+			db.Model(currentUser).Association("HostedEvents").Find(&hostedEvents)
+		}
 
-	// Get attended events
-	var attendedEvents []models.Event
-	database.DB.Model(currentUser).Association("AttendedEvents").Find(&attendedEvents)
+		// Get attended events
+		var attendedEvents []models.Event
+		db.Model(currentUser).Association("AttendedEvents").Find(&attendedEvents)
 
-	log.Printf("Fetched avatar for user %s: %s", currentUser.ID, currentUser.AvatarURL)
+		log.Printf("Fetched avatar for user %s: %s", currentUser.ID, currentUser.AvatarURL)
 
-	response := gin.H{
-		"user":                currentUser,
-		"media_gallery":       mediaGallery,
-		"events_attended":     attendedEvents,
-		"events_hosted":       hostedEvents,
-		"events_hosted_count": currentUser.EventsHostedCount,
+		response := gin.H{
+			"user":                currentUser,
+			"media_gallery":       mediaGallery,
+			"events_attended":     attendedEvents,
+			"events_hosted":       hostedEvents,
+			"events_hosted_count": currentUser.EventsHostedCount,
+		}
+		c.JSON(http.StatusOK, response)
 	}
-	c.JSON(http.StatusOK, response)
 }
 
-func updateMe(c *gin.Context) {
-	var updateData schemas.UserUpdate
-	if err := c.ShouldBindJSON(&updateData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+func updateMe(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var updateData schemas.UserUpdate
+		if err := c.ShouldBindJSON(&updateData); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
+		user, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
 
-	currentUser := user.(*models.User)
+		currentUser := user.(*models.User)
 
-	if updateData.Name != nil {
-		currentUser.Name = *updateData.Name
-	}
-	if updateData.AvatarURL != nil {
-		log.Printf("Updating avatar for user %s: %s", currentUser.ID, *updateData.AvatarURL)
-		currentUser.AvatarURL = *updateData.AvatarURL
-	}
-	if updateData.Bio != nil {
-		currentUser.Bio = *updateData.Bio
-	}
-	if updateData.MediaGallery != nil {
-		mediaGalleryJSON, _ := json.Marshal(*updateData.MediaGallery)
-		currentUser.MediaGallery = datatypes.JSON(mediaGalleryJSON)
-	}
-	if updateData.ProfilePhotos != nil {
-		profilePhotosJSON, _ := json.Marshal(*updateData.ProfilePhotos)
-		currentUser.ProfilePhotos = datatypes.JSON(profilePhotosJSON)
-		// Update avatar_url to be the first profile photo if available
-		if len(*updateData.ProfilePhotos) > 0 {
-			currentUser.AvatarURL = (*updateData.ProfilePhotos)[0]
-		}
-	}
-	if updateData.Age != nil {
-		currentUser.Age = *updateData.Age
-	}
-	if updateData.Gender != nil {
-		currentUser.Gender = *updateData.Gender
-	}
-	if updateData.Location != nil {
-		currentUser.Location = *updateData.Location
-	}
-	if updateData.Height != nil {
-		currentUser.Height = *updateData.Height
-	}
-	if updateData.Work != nil {
-		currentUser.Work = *updateData.Work
-	}
-	if updateData.Education != nil {
-		currentUser.Education = *updateData.Education
-	}
-	if updateData.EducationLevel != nil {
-		currentUser.EducationLevel = *updateData.EducationLevel
-	}
-	if updateData.Drinking != nil {
-		currentUser.Drinking = *updateData.Drinking
-	}
-	if updateData.Interests != nil {
-		interestsJSON, _ := json.Marshal(*updateData.Interests)
-		currentUser.Interests = datatypes.JSON(interestsJSON)
-	}
-	if updateData.GovernmentIDURL != nil {
-		currentUser.GovernmentIDURL = *updateData.GovernmentIDURL
-	}
+		// Only the submitted fields are written. The user was loaded when the
+		// request started, and saving it whole would overwrite the wallet
+		// balance and follower count with stale values.
+		updates := map[string]interface{}{}
 
-	// Update influencer-specific fields if user is an influencer
-	if currentUser.Role == models.RoleInfluencer {
-		if updateData.CoverPhotoURL != nil {
-			currentUser.CoverPhotoURL = *updateData.CoverPhotoURL
+		if updateData.Name != nil {
+			currentUser.Name = *updateData.Name
+			updates["name"] = currentUser.Name
 		}
-		if updateData.InstagramHandle != nil {
-			currentUser.InstagramHandle = *updateData.InstagramHandle
+		if updateData.AvatarURL != nil {
+			log.Printf("Updating avatar for user %s: %s", currentUser.ID, *updateData.AvatarURL)
+			currentUser.AvatarURL = *updateData.AvatarURL
+			updates["avatar_url"] = currentUser.AvatarURL
+		}
+		if updateData.Bio != nil {
+			currentUser.Bio = *updateData.Bio
+			updates["bio"] = currentUser.Bio
+		}
+		if updateData.MediaGallery != nil {
+			mediaGalleryJSON, _ := json.Marshal(*updateData.MediaGallery)
+			currentUser.MediaGallery = datatypes.JSON(mediaGalleryJSON)
+			updates["media_gallery"] = currentUser.MediaGallery
+		}
+		if updateData.ProfilePhotos != nil {
+			profilePhotosJSON, _ := json.Marshal(*updateData.ProfilePhotos)
+			currentUser.ProfilePhotos = datatypes.JSON(profilePhotosJSON)
+			updates["profile_photos"] = currentUser.ProfilePhotos
+			// Update avatar_url to be the first profile photo if available
+			if len(*updateData.ProfilePhotos) > 0 {
+				currentUser.AvatarURL = (*updateData.ProfilePhotos)[0].Variant(imaging.VariantMedium)
+				updates["avatar_url"] = currentUser.AvatarURL
+			}
+		}
+		if updateData.Age != nil {
+			currentUser.Age = *updateData.Age
+			updates["age"] = currentUser.Age
+		}
+		if updateData.Gender != nil {
+			currentUser.Gender = *updateData.Gender
+			updates["gender"] = currentUser.Gender
+		}
+		if updateData.Location != nil {
+			currentUser.Location = *updateData.Location
+			updates["location"] = currentUser.Location
+		}
+		if updateData.Height != nil {
+			currentUser.Height = *updateData.Height
+			updates["height"] = currentUser.Height
+		}
+		if updateData.Work != nil {
+			currentUser.Work = *updateData.Work
+			updates["work"] = currentUser.Work
+		}
+		if updateData.Education != nil {
+			currentUser.Education = *updateData.Education
+			updates["education"] = currentUser.Education
+		}
+		if updateData.EducationLevel != nil {
+			currentUser.EducationLevel = *updateData.EducationLevel
+			updates["education_level"] = currentUser.EducationLevel
+		}
+		if updateData.Drinking != nil {
+			currentUser.Drinking = *updateData.Drinking
+			updates["drinking"] = currentUser.Drinking
+		}
+		if updateData.Interests != nil {
+			interestsJSON, _ := json.Marshal(*updateData.Interests)
+			currentUser.Interests = datatypes.JSON(interestsJSON)
+			updates["interests"] = currentUser.Interests
 		}
-	}
 
-	if err := database.DB.Save(currentUser).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
-		return
-	}
+		// Update influencer-specific fields if user is an influencer
+		if currentUser.Role == models.RoleInfluencer {
+			if updateData.CoverPhotoURL != nil {
+				currentUser.CoverPhotoURL = *updateData.CoverPhotoURL
+				updates["cover_photo_url"] = currentUser.CoverPhotoURL
+			}
+			if updateData.InstagramHandle != nil {
+				currentUser.InstagramHandle = *updateData.InstagramHandle
+				updates["instagram_handle"] = currentUser.InstagramHandle
+			}
+		}
 
-	var mediaGallery []string
-	_ = json.Unmarshal(currentUser.MediaGallery, &mediaGallery)
+		if len(updates) > 0 {
+			if err := db.Model(currentUser).Updates(updates).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+				return
+			}
+		}
+		if err := db.First(currentUser, "id = ?", currentUser.ID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+			return
+		}
 
-	// Get hosted events for influencers
-	var hostedEvents []models.Event
-	if currentUser.Role == models.RoleInfluencer {
-		database.DB.Model(currentUser).Association("HostedEvents").Find(&hostedEvents)
-	}
+		var mediaGallery []schemas.ImageRef
+		_ = json.Unmarshal(currentUser.MediaGallery, &mediaGallery)
 
-	// Get attended events
-	var attendedEvents []models.Event
-	database.DB.Model(currentUser).Association("AttendedEvents").Find(&attendedEvents)
+		// Get hosted events for influencers
+		var hostedEvents []models.Event
+		if currentUser.Role == models.RoleInfluencer {
+			db.Model(currentUser).Association("HostedEvents").Find(&hostedEvents)
+		}
 
-	response := gin.H{
-		"user":                currentUser,
-		"media_gallery":       mediaGallery,
-		"events_attended":     attendedEvents,
-		"events_hosted":       hostedEvents,
-		"events_hosted_count": currentUser.EventsHostedCount,
+		// Get attended events
+		var attendedEvents []models.Event
+		db.Model(currentUser).Association("AttendedEvents").Find(&attendedEvents)
+
+		response := gin.H{
+			"user":                currentUser,
+			"media_gallery":       mediaGallery,
+			"events_attended":     attendedEvents,
+			"events_hosted":       hostedEvents,
+			"events_hosted_count": currentUser.EventsHostedCount,
+		}
+		c.JSON(http.StatusOK, response)
 	}
-	c.JSON(http.StatusOK, response)
 }
 
-func getUserProfile(c *gin.Context) {
-	id := c.Param("id")
-	var user models.User
-
-	if err := database.DB.Where("id = ?", id).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			// Create a new user if not found
-			user = models.User{
-				ID:        id,
-				Name:      "New User",
-				Username:  "user_" + id,
-				Role:      models.RoleFan,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			}
-			if err := database.DB.Create(&user).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+func getUserProfile(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var user models.User
+
+		if err := db.Where("id = ?", id).First(&user).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				// Create a new user if not found
+				user = models.User{
+					ID:        id,
+					Name:      "New User",
+					Username:  "user_" + id,
+					Role:      models.RoleFan,
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				}
+				if err := db.Create(&user).Error; err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+					return
+				}
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
 				return
 			}
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
-			return
 		}
-	}
 
-	var mediaGallery []string
-	_ = json.Unmarshal(user.MediaGallery, &mediaGallery)
+		var mediaGallery []schemas.ImageRef
+		_ = json.Unmarshal(user.MediaGallery, &mediaGallery)
 
-	// Get hosted events for influencers
-	var hostedEvents []models.Event
-	if user.Role == models.RoleInfluencer {
-		database.DB.Model(&user).Association("HostedEvents").Find(&hostedEvents)
-	}
+		// Get hosted events for influencers
+		var hostedEvents []models.Event
+		if user.Role == models.RoleInfluencer {
+			db.Model(&user).Association("HostedEvents").Find(&hostedEvents)
+		}
 
-	// Get attended events
-	var attendedEvents []models.Event
-	database.DB.Model(&user).Association("AttendedEvents").Find(&attendedEvents)
+		// Get attended events
+		var attendedEvents []models.Event
+		db.Model(&user).Association("AttendedEvents").Find(&attendedEvents)
 
-	response := gin.H{
-		"user":                user,
-		"media_gallery":       mediaGallery,
-		"events_attended":     attendedEvents,
-		"events_hosted":       hostedEvents,
-		"events_hosted_count": user.EventsHostedCount,
+		response := gin.H{
+			"user":                user,
+			"media_gallery":       mediaGallery,
+			"events_attended":     attendedEvents,
+			"events_hosted":       hostedEvents,
+			"events_hosted_count": user.EventsHostedCount,
+		}
+		c.JSON(http.StatusOK, response)
 	}
-	c.JSON(http.StatusOK, response)
 }
 
-func getAllInfluencers(c *gin.Context) {
-	var influencers []models.User
-	if err := database.DB.Where("role = ?", models.RoleInfluencer).Find(&influencers).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch influencers"})
-		return
+func getAllInfluencers(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var influencers []models.User
+		if err := db.Where("role = ?", models.RoleInfluencer).Find(&influencers).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch influencers"})
+			return
+		}
+		c.JSON(http.StatusOK, influencers)
 	}
-	c.JSON(http.StatusOK, influencers)
 }
 
 // getUserEvents returns all events for the current user with bid status
-func getUserEvents(c *gin.Context) {
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
+func getUserEvents(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
 
-	currentUser := user.(*models.User)
-
-	// Get all events where user has placed a bid
-	var events []models.Event
-	if err := database.DB.
-		Joins("JOIN bids ON bids.event_id = events.id").
-		Where("bids.user_id = ?", currentUser.ID).
-		Preload("Host").
-		Find(&events).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
-		return
-	}
+		currentUser := user.(*models.User)
 
-	// Get bid information for each event
-	type EventWithBid struct {
-		models.Event
-		BidAmount float64 `json:"bid_amount"`
-		BidStatus string  `json:"bid_status"`
-	}
+		// Get all events where user has an active bid
+		var events []models.Event
+		if err := db.
+			Joins("JOIN bids ON bids.event_id = events.id").
+			Where("bids.user_id = ? AND bids.status = ?", currentUser.ID, models.BidStatusActive).
+			Preload("Host").
+			Find(&events).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+			return
+		}
 
-	var eventsWithBids []EventWithBid
-	for _, event := range events {
-		var bid models.Bid
-		if err := database.DB.Where("event_id = ? AND user_id = ?", event.ID, currentUser.ID).First(&bid).Error; err != nil {
-			continue
+		// Get bid information for each event
+		type EventWithBid struct {
+			models.Event
+			BidAmount float64 `json:"bid_amount"`
+			BidStatus string  `json:"bid_status"`
 		}
 
-		// Determine bid status
-		bidStatus := "pending"
-		if event.BidDeadline.Before(time.Now()) {
-			bidStatus = "expired"
+		var eventsWithBids []EventWithBid
+		for _, event := range events {
+			var bid models.Bid
+			if err := db.Where("event_id = ? AND user_id = ? AND status = ?", event.ID, currentUser.ID, models.BidStatusActive).First(&bid).Error; err != nil {
+				continue
+			}
+
+			// Determine bid status
+			bidStatus := "pending"
+			if event.BidDeadline.Before(time.Now()) {
+				bidStatus = "expired"
+			}
+
+			eventsWithBids = append(eventsWithBids, EventWithBid{
+				Event:     event,
+				BidAmount: bid.Amount,
+				BidStatus: bidStatus,
+			})
 		}
 
-		eventsWithBids = append(eventsWithBids, EventWithBid{
-			Event:     event,
-			BidAmount: bid.Amount,
-			BidStatus: bidStatus,
+		c.JSON(http.StatusOK, gin.H{
+			"events": eventsWithBids,
 		})
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"events": eventsWithBids,
-	})
 }
@@ -1,11 +1,23 @@
 package api
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"spotlight-backend-go/internal/schemas"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"gorm.io/datatypes"
 )
 
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
 // ToJSON converts a slice of strings to datatypes.JSON
 func ToJSON(slice []string) datatypes.JSON {
 	if len(slice) == 0 {
@@ -14,3 +26,75 @@ func ToJSON(slice []string) datatypes.JSON {
 	b, _ := json.Marshal(slice)
 	return datatypes.JSON(b)
 }
+
+// imagesToJSON converts image references to datatypes.JSON
+func imagesToJSON(images []schemas.ImageRef) datatypes.JSON {
+	if len(images) == 0 {
+		return datatypes.JSON([]byte("[]"))
+	}
+	b, _ := json.Marshal(images)
+	return datatypes.JSON(b)
+}
+
+// parsePagination reads the page and limit query parameters, falling back
+// to sane defaults for missing or invalid values
+func parsePagination(c *gin.Context) (page int, limit int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err = strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageSize)))
+	if err != nil || limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	return page, limit
+}
+
+// encodeCursor builds an opaque pagination cursor from a sort key and ID
+func encodeCursor(key string, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key + "|" + id))
+}
+
+// decodeCursor splits a cursor produced by encodeCursor
+func decodeCursor(cursor string) (key string, id string, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", errors.New("invalid cursor")
+	}
+	key, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return "", "", errors.New("invalid cursor")
+	}
+	return key, id, nil
+}
+
+// encodeTimeCursor builds a cursor for lists ordered by a timestamp and ID
+func encodeTimeCursor(t time.Time, id string) string {
+	return encodeCursor(t.UTC().Format(time.RFC3339Nano), id)
+}
+
+// decodeTimeCursor parses a cursor produced by encodeTimeCursor
+func decodeTimeCursor(cursor string) (time.Time, string, error) {
+	key, id, err := decodeCursor(cursor)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	t, err := time.Parse(time.RFC3339Nano, key)
+	if err != nil {
+		return time.Time{}, "", errors.New("invalid cursor")
+	}
+	return t, id, nil
+}
+
+// parseDateParam parses a date query parameter given either as YYYY-MM-DD
+// or as an RFC 3339 timestamp, and reports which form it was
+func parseDateParam(value string) (t time.Time, dateOnly bool, err error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, value)
+	return t, false, err
+}
@@ -0,0 +1,610 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/notifications"
+	"spotlight-backend-go/internal/realtime"
+	"spotlight-backend-go/internal/storage"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errVerificationClosed = errors.New("verification request already decided")
+	errInvalidDocument    = errors.New("document is not a JPEG, PNG or PDF")
+)
+
+// legacyDocumentRejection is the reason given for open requests whose
+// document could not be moved to the private store
+const legacyDocumentRejection = "The document could not be found. Please upload it again."
+
+const (
+	// maxDocumentSize is the largest identity document accepted
+	maxDocumentSize = 10 * 1024 * 1024
+	// documentURLExpiry is how long a signed document URL stays valid
+	documentURLExpiry = 5 * time.Minute
+	// documentUploadTTL is how long an uploaded document is kept before it
+	// must be submitted
+	documentUploadTTL = 24 * time.Hour
+)
+
+// documentTypes maps the sniffed content types accepted for identity
+// documents to the extension they are stored with
+var documentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+// RegisterVerificationRoutes registers the user-facing identity verification
+// routes. Documents are kept in the private store.
+func RegisterVerificationRoutes(router *gin.RouterGroup, db *gorm.DB, documents storage.BlobStore) {
+	verificationGroup := router.Group("/verification")
+	{
+		verificationGroup.GET("", getMyVerification(db))
+		verificationGroup.POST("", submitVerification(db, documents))
+		verificationGroup.POST("/document", uploadVerificationDocument(db, documents))
+		verificationGroup.GET("/:id/document", getVerificationDocument(db, documents, false))
+	}
+}
+
+// registerAdminVerificationRoutes registers the admin review queue
+func registerAdminVerificationRoutes(router *gin.RouterGroup, db *gorm.DB, hub *realtime.Hub, documents storage.BlobStore) {
+	verificationGroup := router.Group("/verifications")
+	{
+		verificationGroup.GET("", getVerificationQueue(db))
+		verificationGroup.GET("/:id", getVerificationRequest(db))
+		verificationGroup.GET("/:id/document", getVerificationDocument(db, documents, true))
+		verificationGroup.POST("/:id/review", startVerificationReview(db))
+		verificationGroup.POST("/:id/approve", approveVerification(db, hub, documents))
+		verificationGroup.POST("/:id/reject", rejectVerification(db, hub, documents))
+	}
+}
+
+// getMyVerification returns the current user's verification history, newest first
+func getMyVerification(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+
+		var requests []models.VerificationRequest
+		if err := db.Where("user_id = ?", userID).Order("created_at DESC").Find(&requests).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch verification requests"})
+			return
+		}
+
+		user := c.MustGet("user").(*models.User)
+		c.JSON(http.StatusOK, gin.H{
+			"is_verified": user.IsVerified,
+			"verified_at": user.VerifiedAt,
+			"requests":    requests,
+		})
+	}
+}
+
+// uploadVerificationDocument stores a government ID document in the
+// private store and returns the key to submit it with. The document is
+// never publicly readable, and is deleted if it is not submitted within
+// documentUploadTTL.
+func uploadVerificationDocument(db *gorm.DB, documents storage.BlobStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+
+		file, err := c.FormFile("document")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No document file provided"})
+			return
+		}
+		if file.Size > maxDocumentSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "File size too large. Maximum size is 10MB"})
+			return
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(src, maxDocumentSize))
+		src.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+			return
+		}
+
+		contentType := http.DetectContentType(data)
+		ext, ok := documentTypes[contentType]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file type. Only JPEG, PNG and PDF documents are allowed"})
+			return
+		}
+
+		key := documentKeyPrefix(userID) + uuid.New().String() + ext
+		if err := documents.Put(c.Request.Context(), key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+			log.Printf("Error storing verification document for user %s in %s store: %v", userID, documents.Name(), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save document"})
+			return
+		}
+		if err := db.Create(&models.DocumentUpload{Key: key, UserID: userID}).Error; err != nil {
+			log.Printf("Error recording verification document %s: %v", key, err)
+			if err := documents.Delete(c.Request.Context(), key); err != nil {
+				log.Printf("Error deleting verification document %s: %v", key, err)
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save document"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"document_key": key, "expires_at": time.Now().Add(documentUploadTTL)})
+	}
+}
+
+// submitVerification creates a verification request for a government ID
+// document uploaded through uploadVerificationDocument
+func submitVerification(db *gorm.DB, documents storage.BlobStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			DocumentKey  string `json:"document_key" binding:"required"`
+			DocumentType string `json:"document_type" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		user := c.MustGet("user").(*models.User)
+		if user.IsVerified {
+			c.JSON(http.StatusConflict, gin.H{"error": "Account is already verified"})
+			return
+		}
+
+		// The key must name a document this user uploaded
+		key := strings.TrimSpace(req.DocumentKey)
+		if !strings.HasPrefix(key, documentKeyPrefix(user.ID)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document key"})
+			return
+		}
+		document, err := documents.Get(c.Request.Context(), key)
+		if err != nil {
+			if err == storage.ErrNotFound || err == storage.ErrInvalidKey {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Document not found. Upload it first"})
+				return
+			}
+			log.Printf("Error checking verification document %s: %v", key, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit verification"})
+			return
+		}
+		document.Close()
+
+		var open int64
+		if err := db.Model(&models.VerificationRequest{}).
+			Where("user_id = ? AND status IN ?", user.ID, []models.VerificationStatus{
+				models.VerificationStatusSubmitted,
+				models.VerificationStatusUnderReview,
+			}).
+			Count(&open).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit verification"})
+			return
+		}
+		if open > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "A verification request is already pending"})
+			return
+		}
+
+		request := models.VerificationRequest{
+			ID:           uuid.New().String(),
+			UserID:       user.ID,
+			DocumentType: strings.TrimSpace(req.DocumentType),
+			DocumentKey:  key,
+			Status:       models.VerificationStatusSubmitted,
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&request).Error; err != nil {
+				return err
+			}
+			// The document is no longer an unsubmitted upload
+			return tx.Where("key = ?", key).Delete(&models.DocumentUpload{}).Error
+		}); err != nil {
+			log.Printf("Error submitting verification for user %s: %v", user.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit verification"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, request)
+	}
+}
+
+// getVerificationQueue returns open verification requests, oldest first.
+// Pass status to look at a specific state instead.
+func getVerificationQueue(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit := parsePagination(c)
+
+		query := db.Model(&models.VerificationRequest{})
+		if status := c.Query("status"); status != "" {
+			query = query.Where("status = ?", status)
+		} else {
+			query = query.Where("status IN ?", []models.VerificationStatus{
+				models.VerificationStatusSubmitted,
+				models.VerificationStatusUnderReview,
+			})
+		}
+
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch verification requests"})
+			return
+		}
+
+		var requests []models.VerificationRequest
+		if err := query.Preload("User").
+			Order("created_at ASC").
+			Offset((page - 1) * limit).Limit(limit).
+			Find(&requests).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch verification requests"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"requests": requests,
+			"total":    total,
+			"page":     page,
+			"limit":    limit,
+		})
+	}
+}
+
+// getVerificationRequest returns a single verification request with its user
+func getVerificationRequest(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.VerificationRequest
+		if err := db.Preload("User").First(&request, "id = ?", c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Verification request not found"})
+			return
+		}
+		c.JSON(http.StatusOK, request)
+	}
+}
+
+// getVerificationDocument returns a short-lived signed URL for a request's
+// document. Users can only fetch their own documents; admins any of them.
+func getVerificationDocument(db *gorm.DB, documents storage.BlobStore, admin bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := db.Where("id = ?", c.Param("id"))
+		if !admin {
+			query = query.Where("user_id = ?", c.GetString("user_id"))
+		}
+		var request models.VerificationRequest
+		if err := query.First(&request).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Verification request not found"})
+			return
+		}
+
+		if request.DocumentKey == "" {
+			// Legacy documents are moved to the private store at startup;
+			// one that is left was never there or could not be moved
+			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+			return
+		}
+		if request.DocumentDeletedAt != nil {
+			c.JSON(http.StatusGone, gin.H{"error": "The document was deleted after the verification was decided"})
+			return
+		}
+
+		signedURL, err := documents.SignedURL(c.Request.Context(), request.DocumentKey, documentURLExpiry)
+		if err != nil {
+			log.Printf("Error signing verification document %s: %v", request.DocumentKey, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch document"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"url":        signedURL,
+			"expires_at": time.Now().Add(documentURLExpiry),
+		})
+	}
+}
+
+// startVerificationReview claims a submitted request for the calling admin
+func startVerificationReview(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reviewerID := c.GetString("user_id")
+
+		result := db.Model(&models.VerificationRequest{}).
+			Where("id = ? AND status = ?", c.Param("id"), models.VerificationStatusSubmitted).
+			Updates(map[string]interface{}{
+				"status":      models.VerificationStatusUnderReview,
+				"reviewer_id": reviewerID,
+			})
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update verification request"})
+			return
+		}
+		if result.RowsAffected == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Verification request is not awaiting review"})
+			return
+		}
+
+		var request models.VerificationRequest
+		db.First(&request, "id = ?", c.Param("id"))
+		c.JSON(http.StatusOK, request)
+	}
+}
+
+// approveVerification marks the user as verified
+func approveVerification(db *gorm.DB, hub *realtime.Hub, documents storage.BlobStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		decideVerification(c, db, hub, documents, models.VerificationStatusApproved, "")
+	}
+}
+
+// rejectVerification rejects a request with a reason shown to the user
+func rejectVerification(db *gorm.DB, hub *realtime.Hub, documents storage.BlobStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Reason string `json:"reason" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A rejection reason is required"})
+			return
+		}
+		decideVerification(c, db, hub, documents, models.VerificationStatusRejected, strings.TrimSpace(req.Reason))
+	}
+}
+
+// decideVerification applies an admin decision to a verification request,
+// updates the user's verified state and notifies them, all in one
+// transaction. The document is deleted once the decision is committed.
+func decideVerification(c *gin.Context, db *gorm.DB, hub *realtime.Hub, documents storage.BlobStore, status models.VerificationStatus, reason string) {
+	reviewerID := c.GetString("user_id")
+
+	var request models.VerificationRequest
+	err := hub.Transaction(db, func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&request, "id = ?", c.Param("id")).Error; err != nil {
+			return err
+		}
+		if !request.IsOpen() {
+			return errVerificationClosed
+		}
+
+		now := time.Now()
+		request.Status = status
+		request.RejectionReason = reason
+		request.ReviewerID = &reviewerID
+		request.ReviewedAt = &now
+		if err := tx.Save(&request).Error; err != nil {
+			return err
+		}
+
+		userUpdates := map[string]interface{}{
+			"is_verified":       status == models.VerificationStatusApproved,
+			"verified_at":       nil,
+			"government_id_url": "",
+		}
+		if status == models.VerificationStatusApproved {
+			userUpdates["verified_at"] = now
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", request.UserID).Updates(userUpdates).Error; err != nil {
+			return err
+		}
+
+		notification := models.Notification{
+			UserID:  request.UserID,
+			Type:    models.NotificationTypeVerification,
+			Title:   "Your account is verified",
+			Message: "Your identity document was approved.",
+		}
+		if status == models.VerificationStatusRejected {
+			notification.Title = "Verification rejected"
+			notification.Message = "Your identity document was rejected: " + reason
+		}
+		return notifications.Create(tx, &notification)
+	})
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Verification request not found"})
+		case errVerificationClosed:
+			c.JSON(http.StatusConflict, gin.H{"error": "Verification request has already been decided"})
+		default:
+			log.Printf("Error deciding verification %s: %v", c.Param("id"), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update verification request"})
+		}
+		return
+	}
+
+	if err := deleteVerificationDocument(c.Request.Context(), db, documents, &request); err != nil {
+		// PurgeVerificationDocuments retries at the next start
+		log.Printf("Error deleting document of verification %s: %v", request.ID, err)
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+// PurgeVerificationDocuments deletes the documents of decided verification
+// requests that are still stored, such as those whose deletion failed, and
+// uploads that were not submitted within documentUploadTTL
+func PurgeVerificationDocuments(ctx context.Context, db *gorm.DB, documents storage.BlobStore) error {
+	var requests []models.VerificationRequest
+	if err := db.Where("status IN ? AND document_key <> '' AND document_deleted_at IS NULL", []models.VerificationStatus{
+		models.VerificationStatusApproved,
+		models.VerificationStatusRejected,
+	}).Find(&requests).Error; err != nil {
+		return err
+	}
+	for i := range requests {
+		if err := deleteVerificationDocument(ctx, db, documents, &requests[i]); err != nil {
+			return err
+		}
+	}
+
+	var uploads []models.DocumentUpload
+	if err := db.Where("created_at < ?", time.Now().Add(-documentUploadTTL)).Find(&uploads).Error; err != nil {
+		return err
+	}
+	for _, upload := range uploads {
+		if err := documents.Delete(ctx, upload.Key); err != nil {
+			return err
+		}
+		if err := db.Where("key = ?", upload.Key).Delete(&models.DocumentUpload{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateLegacyVerificationDocuments moves the documents of requests
+// submitted before the private store out of the public uploads store. Open
+// requests get a private copy; an open request whose document cannot be
+// copied is rejected so the user uploads it again. The public copies and
+// the URLs pointing at them, including users.government_id_url, are removed.
+func MigrateLegacyVerificationDocuments(ctx context.Context, db *gorm.DB, public storage.BlobStore, documents storage.BlobStore) error {
+	var requests []models.VerificationRequest
+	if err := db.Where("document_url <> '' AND COALESCE(document_key, '') = ''").Find(&requests).Error; err != nil {
+		return err
+	}
+	for i := range requests {
+		if err := migrateLegacyDocument(ctx, db, public, documents, &requests[i]); err != nil {
+			return fmt.Errorf("verification %s: %w", requests[i].ID, err)
+		}
+	}
+
+	var users []models.User
+	if err := db.Unscoped().Select("id", "government_id_url").
+		Where("government_id_url <> ''").
+		Find(&users).Error; err != nil {
+		return err
+	}
+	for _, user := range users {
+		if key, ok := publicKey(public, user.GovernmentIDURL); ok {
+			if err := public.Delete(ctx, key); err != nil {
+				return err
+			}
+		}
+		if err := db.Unscoped().Model(&models.User{}).Where("id = ?", user.ID).
+			Update("government_id_url", "").Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateLegacyDocument moves one legacy request's document to the private
+// store, or rejects the request if it is open and the document is unusable
+func migrateLegacyDocument(ctx context.Context, db *gorm.DB, public storage.BlobStore, documents storage.BlobStore, request *models.VerificationRequest) error {
+	oldKey, found := publicKey(public, request.DocumentURL)
+
+	var newKey string
+	if request.IsOpen() && found {
+		key, err := copyLegacyDocument(ctx, public, documents, request.UserID, oldKey)
+		if err != nil && err != storage.ErrNotFound && err != errInvalidDocument {
+			return err
+		}
+		newKey = key
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"document_url": ""}
+	if newKey != "" {
+		updates["document_key"] = newKey
+	} else {
+		updates["document_deleted_at"] = now
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if request.IsOpen() && newKey == "" {
+			updates["status"] = models.VerificationStatusRejected
+			updates["rejection_reason"] = legacyDocumentRejection
+			updates["reviewed_at"] = now
+			if err := notifications.Create(tx, &models.Notification{
+				UserID:  request.UserID,
+				Type:    models.NotificationTypeVerification,
+				Title:   "Verification rejected",
+				Message: "Your identity document was rejected: " + legacyDocumentRejection,
+			}); err != nil {
+				return err
+			}
+		}
+		return tx.Model(request).Updates(updates).Error
+	})
+	if err != nil {
+		if newKey != "" {
+			documents.Delete(ctx, newKey)
+		}
+		return err
+	}
+
+	if found {
+		return public.Delete(ctx, oldKey)
+	}
+	return nil
+}
+
+// copyLegacyDocument copies a document from the public store to the private
+// one and returns its private key
+func copyLegacyDocument(ctx context.Context, public storage.BlobStore, documents storage.BlobStore, userID string, key string) (string, error) {
+	src, err := public.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(io.LimitReader(src, maxDocumentSize))
+	src.Close()
+	if err != nil {
+		return "", err
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := documentTypes[contentType]
+	if !ok {
+		return "", errInvalidDocument
+	}
+	privateKey := documentKeyPrefix(userID) + uuid.New().String() + ext
+	if err := documents.Put(ctx, privateKey, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return "", err
+	}
+	return privateKey, nil
+}
+
+// publicKey returns the key of the blob a public store URL points at
+func publicKey(public storage.BlobStore, rawURL string) (string, bool) {
+	prefix := public.URL("")
+	if !strings.HasPrefix(rawURL, prefix) {
+		return "", false
+	}
+	key, err := url.PathUnescape(strings.TrimPrefix(rawURL, prefix))
+	if err != nil || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+// deleteVerificationDocument removes a request's document from the private
+// store and records when it was deleted
+func deleteVerificationDocument(ctx context.Context, db *gorm.DB, documents storage.BlobStore, request *models.VerificationRequest) error {
+	if request.DocumentKey == "" || request.DocumentDeletedAt != nil {
+		return nil
+	}
+	if err := documents.Delete(ctx, request.DocumentKey); err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := db.Model(request).Update("document_deleted_at", now).Error; err != nil {
+		return err
+	}
+	request.DocumentDeletedAt = &now
+	return nil
+}
+
+// documentKeyPrefix is the private store prefix of a user's documents
+func documentKeyPrefix(userID string) string {
+	return "government-ids/" + userID + "/"
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"spotlight-backend-go/internal/storage"
+	"testing"
+)
+
+func TestPublicKey(t *testing.T) {
+	local := storage.NewLocalStore(t.TempDir(), "http://localhost:8080/uploads", "http://localhost:8080/files", "secret")
+	s3, err := storage.NewS3Store(storage.S3Options{
+		Endpoint:        "https://s3.us-east-1.amazonaws.com",
+		Region:          "us-east-1",
+		Bucket:          "spotlight-uploads",
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+		PublicURL:       "https://cdn.example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		store  storage.BlobStore
+		rawURL string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "local document",
+			store:  local,
+			rawURL: "http://localhost:8080/uploads/government-ids/u1.jpg",
+			want:   "government-ids/u1.jpg",
+			wantOK: true,
+		},
+		{
+			name:   "s3 document with escaped characters",
+			store:  s3,
+			rawURL: "https://cdn.example.com/government-ids/my%20id.pdf",
+			want:   "government-ids/my id.pdf",
+			wantOK: true,
+		},
+		{
+			name:   "another host",
+			store:  local,
+			rawURL: "https://elsewhere.example.com/uploads/government-ids/u1.jpg",
+		},
+		{
+			name:   "store root",
+			store:  local,
+			rawURL: "http://localhost:8080/uploads/",
+		},
+		{
+			name:  "empty",
+			store: local,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := publicKey(tt.store, tt.rawURL)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("publicKey(%q) = %q, %v, want %q, %v", tt.rawURL, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
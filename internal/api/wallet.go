@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/payments"
+	"spotlight-backend-go/internal/realtime"
+	"spotlight-backend-go/internal/wallet"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RegisterWalletRoutes registers wallet-related routes. Top-ups and
+// withdrawals are only available when provider is not nil.
+func RegisterWalletRoutes(router *gin.RouterGroup, db *gorm.DB, provider payments.PaymentProvider, hub *realtime.Hub) {
+	walletGroup := router.Group("/wallet")
+	{
+		walletGroup.GET("", getWallet(db))
+		walletGroup.GET("/transactions", getWalletTransactions(db))
+		if provider != nil {
+			walletGroup.POST("/topup", createTopUp(db, provider))
+			walletGroup.POST("/withdraw", createWithdrawal(db, provider, hub))
+		}
+		walletGroup.GET("/withdrawals", getWithdrawals(db))
+		walletGroup.POST("/withdrawals/:id/cancel", cancelWithdrawal(db))
+	}
+}
+
+// getWallet returns the current user's ledger balance and escrowed funds
+func getWallet(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+
+		balance, err := wallet.Balance(db, wallet.WalletAccount(userID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wallet"})
+			return
+		}
+		escrow, err := wallet.EscrowBalance(db, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wallet"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"balance": balance,
+			"escrow":  escrow,
+		})
+	}
+}
+
+// getWalletTransactions returns the current user's transactions, newest first
+func getWalletTransactions(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		page, limit := parsePagination(c)
+
+		query := db.Model(&models.Transaction{}).Where("user_id = ?", userID)
+		if txnType := c.Query("type"); txnType != "" {
+			query = query.Where("type = ?", txnType)
+		}
+
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
+			return
+		}
+
+		var transactions []models.Transaction
+		if err := query.Order("created_at DESC, id DESC").
+			Offset((page - 1) * limit).Limit(limit).
+			Find(&transactions).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"transactions": transactions,
+			"total":        total,
+			"page":         page,
+			"limit":        limit,
+		})
+	}
+}
+
+// reconcileWallets reports users whose cached wallet balance disagrees with
+// the ledger
+func reconcileWallets(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mismatches, err := wallet.Reconcile(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile wallets"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"mismatches": mismatches,
+			"count":      len(mismatches),
+		})
+	}
+}
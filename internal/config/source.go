@@ -0,0 +1,88 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// source looks up settings in the environment, then in the config file
+type source struct {
+	file map[string]string
+	// problems collects the settings that could not be parsed
+	problems []string
+}
+
+// newSource reads the file named by CONFIG_FILE, if any
+func newSource() (*source, error) {
+	src := &source{file: map[string]string{}}
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		return src, nil
+	}
+	values, err := readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file %s: %w", path, err)
+	}
+	src.file = values
+	return src, nil
+}
+
+// readFile parses a file of KEY=VALUE lines, the format of .env files.
+// Blank lines and lines starting with # are skipped, and values may be
+// wrapped in single or double quotes.
+func readFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", line)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// string returns the setting, or fallback when it is unset or empty
+func (s *source) string(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	if value := s.file[key]; value != "" {
+		return value
+	}
+	return fallback
+}
+
+// bool returns the setting parsed as a boolean, or fallback when it is
+// unset
+func (s *source) bool(key string, fallback bool) bool {
+	value := s.string(key, "")
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		s.problems = append(s.problems, fmt.Sprintf("%s must be true or false, got %q", key, value))
+		return fallback
+	}
+	return b
+}
@@ -1,10 +1,9 @@
 package database
 
 import (
-	"fmt"
 	"log"
 	"os"
-	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/config"
 	"time"
 
 	"gorm.io/driver/postgres"
@@ -14,14 +13,7 @@ import (
 
 var DB *gorm.DB
 
-func InitDB() *gorm.DB {
-	// Get database configuration from environment variables
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
-
+func InitDB(cfg config.Database) *gorm.DB {
 	// Configure GORM logger
 	newLogger := logger.New(
 		log.New(os.Stdout, "\r\n", log.LstdFlags),
@@ -33,31 +25,16 @@ func InitDB() *gorm.DB {
 		},
 	)
 
-	// Create DSN for PostgreSQL
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		dbHost, dbUser, dbPassword, dbName, dbPort)
-
 	// Open database connection
 	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
+	DB, err = gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{
 		Logger: newLogger,
 	})
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Auto migrate the schema
-	err = DB.AutoMigrate(
-		&models.User{},
-		&models.Event{},
-		&models.EventAttendee{},
-		&models.Bid{},
-		&models.Application{},
-	)
-	if err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
-	}
-
-	log.Println("Database connected and migrated successfully!")
+	// The schema comes from the versioned migrations; see RunMigrations
+	log.Println("Database connected successfully!")
 	return DB
 }
@@ -1,28 +1,25 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
+	"spotlight-backend-go/internal/config"
+	"spotlight-backend-go/internal/migrate"
 	"time"
 
 	_ "github.com/lib/pq"
-	_ "gorm.io/driver/postgres"
 )
 
-func RunMigrations() error {
-	// Get database configuration from environment variables
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
+// MigrationsDir is where the migration files are read from
+const MigrationsDir = "migrations"
 
-	// Create connection string
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		dbHost, dbPort, dbUser, dbPassword, dbName)
+// OpenSQL connects to the database, retrying for up to 30 seconds while it
+// starts
+func OpenSQL(cfg config.Database) (*sql.DB, error) {
+	connStr := cfg.DSN()
 
 	// Try to connect to database with retries
 	var db *sql.DB
@@ -32,30 +29,66 @@ func RunMigrations() error {
 		if err == nil {
 			err = db.Ping()
 			if err == nil {
-				break
+				return db, nil
 			}
+			db.Close()
 		}
 		log.Printf("Waiting for database connection... (%d/30)", i+1)
 		time.Sleep(time.Second)
 	}
+	return nil, fmt.Errorf("failed to connect to database after 30 attempts: %v", err)
+}
+
+// NewMigrator returns a migrator for the files in MigrationsDir
+func NewMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	return migrate.New(db, os.DirFS(MigrationsDir))
+}
+
+// RunMigrations applies the pending migrations
+func RunMigrations(cfg config.Database) error {
+	db, err := OpenSQL(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to connect to database after 30 attempts: %v", err)
+		return err
 	}
 	defer db.Close()
 
-	// Read and execute the complete schema
-	schemaPath := "migrations/complete_schema.sql"
-	schemaSQL, err := ioutil.ReadFile(schemaPath)
+	migrator, err := NewMigrator(db)
 	if err != nil {
-		return fmt.Errorf("failed to read schema file: %v", err)
+		return fmt.Errorf("failed to load migrations: %v", err)
+	}
+	applied, err := migrator.Up(context.Background())
+	for _, m := range applied {
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
 	}
-
-	// Execute the schema
-	_, err = db.Exec(string(schemaSQL))
 	if err != nil {
-		return fmt.Errorf("failed to execute schema: %v", err)
+		return fmt.Errorf("failed to run migrations: %v", err)
 	}
 
 	log.Println("Database migrations completed successfully!")
 	return nil
-} 
\ No newline at end of file
+}
+
+// WarnPendingMigrations logs when migrations are waiting to be applied, for
+// deployments that migrate separately from startup
+func WarnPendingMigrations(cfg config.Database) {
+	db, err := OpenSQL(cfg)
+	if err != nil {
+		log.Printf("Could not check for pending migrations: %v", err)
+		return
+	}
+	defer db.Close()
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		log.Printf("Could not check for pending migrations: %v", err)
+		return
+	}
+	pending, err := migrator.Pending(context.Background())
+	if err != nil {
+		log.Printf("Could not check for pending migrations: %v", err)
+		return
+	}
+	if pending > 0 {
+		log.Printf("WARNING: %d migration(s) pending; run `migrate up`", pending)
+	}
+}
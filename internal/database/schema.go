@@ -0,0 +1,16 @@
+package database
+
+import (
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/schemacheck"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// CheckSchema compares every model with the live database schema
+func CheckSchema(db *gorm.DB) ([]schemacheck.Problem, error) {
+	// The introspection query is not worth logging
+	quiet := db.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
+	return schemacheck.Check(quiet, models.All()...)
+}
@@ -3,10 +3,12 @@ package database
 import (
 	"log"
 	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/wallet"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 func SeedData() {
@@ -32,7 +34,6 @@ func SeedData() {
 			WalletBalance:   5000.0,
 			MediaGallery:    datatypes.JSON([]byte("[]")),
 			CoverPhotoURL:   "https://api.dicebear.com/7.x/avataaars/svg?seed=RahulCover",
-			FollowerCount:   50000,
 			InstagramHandle: "@rahulsharma",
 			Verified:        true,
 			Age:             28,
@@ -59,7 +60,6 @@ func SeedData() {
 			WalletBalance:   5000.0,
 			MediaGallery:    datatypes.JSON([]byte("[]")),
 			CoverPhotoURL:   "https://api.dicebear.com/7.x/avataaars/svg?seed=RahulCover",
-			FollowerCount:   50000,
 			InstagramHandle: "@ajay_gaurkhede",
 			Verified:        true,
 			Age:             28,
@@ -86,7 +86,6 @@ func SeedData() {
 			WalletBalance:   5000.0,
 			MediaGallery:    datatypes.JSON([]byte("[]")),
 			CoverPhotoURL:   "https://api.dicebear.com/7.x/avataaars/svg?seed=RahulCover",
-			FollowerCount:   50000,
 			InstagramHandle: "@digvijay_singh",
 			Verified:        true,
 			Age:             28,
@@ -113,7 +112,6 @@ func SeedData() {
 			WalletBalance:   5000.0,
 			MediaGallery:    datatypes.JSON([]byte("[]")),
 			CoverPhotoURL:   "https://api.dicebear.com/7.x/avataaars/svg?seed=RahulCover",
-			FollowerCount:   50000,
 			InstagramHandle: "@digvijay_kumar",
 			Verified:        true,
 			Age:             34,
@@ -140,7 +138,6 @@ func SeedData() {
 			WalletBalance:   5000.0,
 			MediaGallery:    datatypes.JSON([]byte("[]")),
 			CoverPhotoURL:   "https://api.dicebear.com/7.x/avataaars/svg?seed=RahulCover",
-			FollowerCount:   50000,
 			InstagramHandle: "@digvijay_kumar",
 			Verified:        true,
 			Age:             28,
@@ -167,7 +164,6 @@ func SeedData() {
 			WalletBalance:   4500.0,
 			MediaGallery:    datatypes.JSON([]byte("[]")),
 			CoverPhotoURL:   "https://api.dicebear.com/7.x/avataaars/svg?seed=PriyaCover",
-			FollowerCount:   75000,
 			InstagramHandle: "@priyapatel",
 			Verified:        true,
 			Age:             25,
@@ -194,7 +190,6 @@ func SeedData() {
 			WalletBalance:   3500.0,
 			MediaGallery:    datatypes.JSON([]byte("[]")),
 			CoverPhotoURL:   "https://api.dicebear.com/7.x/avataaars/svg?seed=ArjunCover",
-			FollowerCount:   30000,
 			InstagramHandle: "@arjunsingh",
 			Verified:        true,
 			Age:             32,
@@ -221,7 +216,6 @@ func SeedData() {
 			WalletBalance:   4000.0,
 			MediaGallery:    datatypes.JSON([]byte("[]")),
 			CoverPhotoURL:   "https://api.dicebear.com/7.x/avataaars/svg?seed=AnanyaCover",
-			FollowerCount:   45000,
 			InstagramHandle: "@ananyagupta",
 			Verified:        true,
 			Age:             27,
@@ -248,7 +242,6 @@ func SeedData() {
 			WalletBalance:   5500.0,
 			MediaGallery:    datatypes.JSON([]byte("[]")),
 			CoverPhotoURL:   "https://api.dicebear.com/7.x/avataaars/svg?seed=VikramCover",
-			FollowerCount:   60000,
 			InstagramHandle: "@vikrammalhotra",
 			Verified:        true,
 			Age:             30,
@@ -385,9 +378,26 @@ func SeedData() {
 	}
 
 	// Create all users
+	var created []models.User
 	for _, user := range append(influencers, fans...) {
 		if err := DB.Create(&user).Error; err != nil {
 			log.Printf("Error creating user %s: %v", user.Email, err)
+			continue
+		}
+		created = append(created, user)
+	}
+
+	// Record the sample balances of the new users in the wallet ledger
+	for _, user := range created {
+		if user.WalletBalance <= 0 {
+			continue
+		}
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			_, err := wallet.Credit(tx, user.ID, user.WalletBalance, models.TransactionTypeOpeningBalance, "Opening balance")
+			return err
+		})
+		if err != nil {
+			log.Printf("Error recording opening balance for %s: %v", user.Email, err)
 		}
 	}
 
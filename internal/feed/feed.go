@@ -0,0 +1,255 @@
+// Package feed ranks upcoming events for a user's home feed. Scoring is
+// pluggable: a Scorer turns a candidate event into a score, and the signal
+// functions here can be combined into custom scorers.
+package feed
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"spotlight-backend-go/internal/models"
+	"strings"
+	"time"
+)
+
+// Reasons attached to scored events so clients can explain a placement
+const (
+	ReasonFollowedHost = "followed_host"
+	ReasonInterest     = "interest"
+	ReasonNearby       = "nearby"
+	ReasonNew          = "new"
+	ReasonPopular      = "popular"
+)
+
+// popularitySaturation is the number of applications and attendees at
+// which an event counts as fully popular
+const popularitySaturation = 100
+
+// Candidate is an event considered for a feed with the counts scorers use
+type Candidate struct {
+	Event            *models.Event
+	AttendeeCount    int64
+	ApplicationCount int64
+}
+
+// Viewer is the user a feed is built for
+type Viewer struct {
+	UserID        string
+	Location      string
+	Interests     []string
+	FollowedHosts map[string]bool
+}
+
+// NewViewer builds a viewer from a user and the IDs of the hosts they follow
+func NewViewer(user *models.User, followedHosts []string) *Viewer {
+	viewer := &Viewer{
+		UserID:        user.ID,
+		Location:      user.Location,
+		FollowedHosts: make(map[string]bool, len(followedHosts)),
+	}
+	var interests []string
+	_ = json.Unmarshal(user.Interests, &interests)
+	for _, interest := range interests {
+		if interest = normalize(interest); interest != "" {
+			viewer.Interests = append(viewer.Interests, interest)
+		}
+	}
+	for _, id := range followedHosts {
+		viewer.FollowedHosts[id] = true
+	}
+	return viewer
+}
+
+// Score is a candidate's rank value and the reasons behind it
+type Score struct {
+	Value   float64
+	Reasons []string
+}
+
+// Scorer scores a candidate for a viewer. Scores must depend only on their
+// arguments so a page can be rebuilt exactly from its cursor.
+type Scorer interface {
+	Score(viewer *Viewer, candidate *Candidate, now time.Time) Score
+}
+
+// ScorerFunc adapts a function to the Scorer interface
+type ScorerFunc func(viewer *Viewer, candidate *Candidate, now time.Time) Score
+
+// Score calls f
+func (f ScorerFunc) Score(viewer *Viewer, candidate *Candidate, now time.Time) Score {
+	return f(viewer, candidate, now)
+}
+
+// WeightedScorer adds up the signals, each multiplied by its weight
+type WeightedScorer struct {
+	FollowedHost float64
+	Interest     float64
+	Location     float64
+	Recency      float64
+	Popularity   float64
+	// RecencyHalfLife is how long after creation an event's recency
+	// signal halves
+	RecencyHalfLife time.Duration
+}
+
+// DefaultScorer favours followed hosts, then interests and location, with
+// recency and popularity to order the rest
+func DefaultScorer() *WeightedScorer {
+	return &WeightedScorer{
+		FollowedHost:    3,
+		Interest:        2,
+		Location:        1.5,
+		Recency:         1,
+		Popularity:      1,
+		RecencyHalfLife: 7 * 24 * time.Hour,
+	}
+}
+
+// Score implements Scorer
+func (s *WeightedScorer) Score(viewer *Viewer, candidate *Candidate, now time.Time) Score {
+	followed := FollowedHost(viewer, candidate)
+	interest := InterestMatch(viewer, candidate)
+	location := LocationMatch(viewer, candidate)
+	recency := Recency(candidate, now, s.RecencyHalfLife)
+	popularity := Popularity(candidate)
+
+	var reasons []string
+	if followed > 0 {
+		reasons = append(reasons, ReasonFollowedHost)
+	}
+	if interest > 0 {
+		reasons = append(reasons, ReasonInterest)
+	}
+	if location > 0 {
+		reasons = append(reasons, ReasonNearby)
+	}
+	if recency >= 0.5 {
+		reasons = append(reasons, ReasonNew)
+	}
+	if popularity >= 0.5 {
+		reasons = append(reasons, ReasonPopular)
+	}
+
+	return Score{
+		Value: s.FollowedHost*followed +
+			s.Interest*interest +
+			s.Location*location +
+			s.Recency*recency +
+			s.Popularity*popularity,
+		Reasons: reasons,
+	}
+}
+
+// FollowedHost is 1 if the viewer follows the event's host
+func FollowedHost(viewer *Viewer, candidate *Candidate) float64 {
+	if viewer.FollowedHosts[candidate.Event.HostID] {
+		return 1
+	}
+	return 0
+}
+
+// InterestMatch is 1 if the event's category is one of the viewer's
+// interests, 0.5 if an interest appears in its title, and 0 otherwise
+func InterestMatch(viewer *Viewer, candidate *Candidate) float64 {
+	category := normalize(candidate.Event.Category)
+	title := normalize(candidate.Event.Title)
+	match := 0.0
+	for _, interest := range viewer.Interests {
+		if interest == category {
+			return 1
+		}
+		if strings.Contains(title, interest) {
+			match = 0.5
+		}
+	}
+	return match
+}
+
+// LocationMatch compares the free-text locations of the viewer and the
+// event. It is 1 when the first part (usually the city) is the same, 0.5
+// when they share any comma separated part, and 0 otherwise.
+func LocationMatch(viewer *Viewer, candidate *Candidate) float64 {
+	viewerParts := locationParts(viewer.Location)
+	eventParts := locationParts(candidate.Event.Location)
+	if len(viewerParts) == 0 || len(eventParts) == 0 {
+		return 0
+	}
+	if viewerParts[0] == eventParts[0] {
+		return 1
+	}
+	for _, v := range viewerParts {
+		for _, e := range eventParts {
+			if v == e {
+				return 0.5
+			}
+		}
+	}
+	return 0
+}
+
+// Recency decays from 1 for a just created event, halving every halfLife
+func Recency(candidate *Candidate, now time.Time, halfLife time.Duration) float64 {
+	if halfLife <= 0 {
+		return 0
+	}
+	age := now.Sub(candidate.Event.CreatedAt)
+	if age < 0 {
+		age = 0
+	}
+	return math.Exp2(-float64(age) / float64(halfLife))
+}
+
+// Popularity grows logarithmically with applications and attendees and
+// reaches 1 at popularitySaturation
+func Popularity(candidate *Candidate) float64 {
+	n := float64(candidate.ApplicationCount + candidate.AttendeeCount)
+	return math.Min(1, math.Log1p(n)/math.Log1p(popularitySaturation))
+}
+
+// Ranked is a candidate with its score
+type Ranked struct {
+	*Candidate
+	Score
+}
+
+// Rank scores the candidates and orders them by score, highest first,
+// breaking ties by event ID so the order is total. Scores are rounded to
+// six decimals so they survive a round trip through a cursor.
+func Rank(scorer Scorer, viewer *Viewer, candidates []Candidate, now time.Time) []Ranked {
+	ranked := make([]Ranked, len(candidates))
+	for i := range candidates {
+		score := scorer.Score(viewer, &candidates[i], now)
+		score.Value = math.Round(score.Value*1e6) / 1e6
+		ranked[i] = Ranked{Candidate: &candidates[i], Score: score}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Value != ranked[j].Value {
+			return ranked[i].Value > ranked[j].Value
+		}
+		return ranked[i].Event.ID < ranked[j].Event.ID
+	})
+	return ranked
+}
+
+// After reports whether r comes after the position (value, eventID) in the
+// order produced by Rank
+func (r *Ranked) After(value float64, eventID string) bool {
+	if r.Value != value {
+		return r.Value < value
+	}
+	return r.Event.ID > eventID
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func locationParts(location string) []string {
+	var parts []string
+	for _, part := range strings.Split(location, ",") {
+		if part = normalize(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
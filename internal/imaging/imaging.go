@@ -0,0 +1,220 @@
+// Package imaging turns uploaded images into sanitized variants. Images are
+// decoded from their sniffed content type, rotated upright according to
+// their EXIF orientation and re-encoded, which drops EXIF, GPS and every
+// other metadata block the original carried.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// Variant names
+const (
+	VariantThumbnail = "thumbnail"
+	VariantMedium    = "medium"
+	VariantLarge     = "large"
+)
+
+// MaxPixels bounds the decoded size of an image so small files with huge
+// dimensions cannot exhaust memory
+const MaxPixels = 40_000_000
+
+// jpegQuality is used when re-encoding opaque variants
+const jpegQuality = 85
+
+var (
+	// ErrUnsupportedType is returned for content that is not a JPEG, PNG or
+	// GIF image
+	ErrUnsupportedType = errors.New("unsupported image type")
+	// ErrTooLarge is returned for images over MaxPixels
+	ErrTooLarge = errors.New("image dimensions too large")
+)
+
+// Spec describes a variant. Images are scaled down to fit within Width x
+// Height, or cropped to fill it exactly when Crop is set. Images are never
+// scaled up.
+type Spec struct {
+	Name   string
+	Width  int
+	Height int
+	Crop   bool
+}
+
+// DefaultSpecs are the variants generated for uploads: a square thumbnail
+// for lists, a medium size for cards and a large size for full screen
+var DefaultSpecs = []Spec{
+	{Name: VariantThumbnail, Width: 320, Height: 320, Crop: true},
+	{Name: VariantMedium, Width: 800, Height: 800},
+	{Name: VariantLarge, Width: 1600, Height: 1600},
+}
+
+// Variant is an encoded variant
+type Variant struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	// Ext is the file extension matching ContentType, with the dot
+	Ext    string
+	Width  int
+	Height int
+}
+
+// contentTypes maps the sniffed content types accepted to their extensions
+var contentTypes = map[string][]string{
+	"image/jpeg": {".jpg", ".jpeg"},
+	"image/png":  {".png"},
+	"image/gif":  {".gif"},
+}
+
+// DetectContentType sniffs the content type of an image from its first
+// bytes. It returns ErrUnsupportedType for anything but JPEG, PNG or GIF.
+func DetectContentType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := contentTypes[contentType]; !ok {
+		return "", ErrUnsupportedType
+	}
+	return contentType, nil
+}
+
+// MatchesExtension reports whether ext (with the dot, lower case) is a
+// file extension used for contentType
+func MatchesExtension(contentType string, ext string) bool {
+	for _, e := range contentTypes[contentType] {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// Process decodes an image and encodes a variant for each spec. Animated
+// GIFs keep only their first frame. Opaque images are encoded as JPEG and
+// images with transparency as PNG.
+func Process(data []byte, specs []Spec) ([]Variant, error) {
+	if _, err := DetectContentType(data); err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	img := toRGBA(src)
+	img = orient(img, jpegOrientation(data))
+	opaque := img.Opaque()
+
+	variants := make([]Variant, 0, len(specs))
+	for _, spec := range specs {
+		resized := resize(img, spec)
+		variant := Variant{
+			Name:   spec.Name,
+			Width:  resized.Bounds().Dx(),
+			Height: resized.Bounds().Dy(),
+		}
+		var buf bytes.Buffer
+		if opaque {
+			err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: jpegQuality})
+			variant.ContentType, variant.Ext = "image/jpeg", ".jpg"
+		} else {
+			err = png.Encode(&buf, resized)
+			variant.ContentType, variant.Ext = "image/png", ".png"
+		}
+		if err != nil {
+			return nil, err
+		}
+		variant.Data = buf.Bytes()
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
+
+// toRGBA copies an image into an RGBA image with its origin at (0, 0)
+func toRGBA(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Src)
+	return dst
+}
+
+// resize scales img down for spec, cropping the centre first if the spec
+// asks for an exact size
+func resize(img *image.RGBA, spec Spec) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	if spec.Crop {
+		// Crop to the spec's aspect ratio, then scale to fit
+		cropW, cropH := w, h
+		if w*spec.Height > h*spec.Width {
+			cropW = h * spec.Width / spec.Height
+		} else {
+			cropH = w * spec.Height / spec.Width
+		}
+		x0, y0 := (w-cropW)/2, (h-cropH)/2
+		img = img.SubImage(image.Rect(x0, y0, x0+cropW, y0+cropH)).(*image.RGBA)
+		w, h = cropW, cropH
+	}
+
+	dstW, dstH := w, h
+	if dstW > spec.Width {
+		dstW, dstH = spec.Width, max(1, h*spec.Width/w)
+	}
+	if dstH > spec.Height {
+		dstW, dstH = max(1, w*spec.Height/h), spec.Height
+	}
+	if dstW == w && dstH == h {
+		return toRGBA(img)
+	}
+	return downsample(img, dstW, dstH)
+}
+
+// downsample shrinks img to w x h by averaging the source pixels each
+// destination pixel covers
+func downsample(img *image.RGBA, w int, h int) *image.RGBA {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0 := y * srcH / h
+		y1 := max(y0+1, (y+1)*srcH/h)
+		for x := 0; x < w; x++ {
+			x0 := x * srcW / w
+			x1 := max(x0+1, (x+1)*srcW/w)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				i := img.PixOffset(bounds.Min.X+x0, bounds.Min.Y+sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint64(img.Pix[i])
+					g += uint64(img.Pix[i+1])
+					b += uint64(img.Pix[i+2])
+					a += uint64(img.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n),
+				G: uint8(g / n),
+				B: uint8(b / n),
+				A: uint8(a / n),
+			})
+		}
+	}
+	return dst
+}
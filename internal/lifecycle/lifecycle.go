@@ -0,0 +1,114 @@
+package lifecycle
+
+import (
+	"errors"
+	"spotlight-backend-go/internal/models"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidTransition is returned when an event cannot move to the
+// requested status, either because the state machine forbids it or because
+// the event changed concurrently
+var ErrInvalidTransition = errors.New("invalid event status transition")
+
+// StatusChange describes an event moving from one status to another. Event
+// already carries the new status.
+type StatusChange struct {
+	Event *models.Event
+	From  models.EventStatus
+	To    models.EventStatus
+}
+
+// StatusHook is called for every status change inside the transaction that
+// makes it. Returning an error rolls the change back.
+type StatusHook func(tx *gorm.DB, change StatusChange) error
+
+// BiddingClosedHook is called when bidding on an event closes, inside the
+// transaction that records it. Returning an error rolls it back.
+type BiddingClosedHook func(tx *gorm.DB, event *models.Event) error
+
+// Engine applies event status changes and runs the hooks other subsystems
+// subscribe with
+type Engine struct {
+	mu            sync.RWMutex
+	statusHooks   []StatusHook
+	biddingClosed []BiddingClosedHook
+}
+
+// New returns an engine with no hooks
+func New() *Engine {
+	return &Engine{}
+}
+
+// OnStatusChange subscribes fn to every status change
+func (e *Engine) OnStatusChange(fn StatusHook) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.statusHooks = append(e.statusHooks, fn)
+}
+
+// OnBiddingClosed subscribes fn to bidding closing on an event
+func (e *Engine) OnBiddingClosed(fn BiddingClosedHook) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.biddingClosed = append(e.biddingClosed, fn)
+}
+
+// Transition moves an event to next and runs the status hooks. The update
+// only applies if the event still has the status it was loaded with.
+func (e *Engine) Transition(tx *gorm.DB, event *models.Event, next models.EventStatus) error {
+	if !event.Status.CanTransitionTo(next) {
+		return ErrInvalidTransition
+	}
+
+	now := time.Now()
+	result := tx.Model(&models.Event{}).
+		Where("id = ? AND status = ?", event.ID, event.Status).
+		Updates(map[string]interface{}{"status": next, "updated_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTransition
+	}
+
+	change := StatusChange{Event: event, From: event.Status, To: next}
+	event.Status = next
+	event.UpdatedAt = now
+
+	e.mu.RLock()
+	hooks := e.statusHooks
+	e.mu.RUnlock()
+	for _, hook := range hooks {
+		if err := hook(tx, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CloseBidding records that bidding on an event has closed and runs the
+// bidding closed hooks. It does nothing if bidding was already closed.
+func (e *Engine) CloseBidding(tx *gorm.DB, event *models.Event) error {
+	now := time.Now()
+	result := tx.Model(&models.Event{}).
+		Where("id = ? AND bidding_closed_at IS NULL", event.ID).
+		Update("bidding_closed_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	event.BiddingClosedAt = &now
+
+	e.mu.RLock()
+	hooks := e.biddingClosed
+	e.mu.RUnlock()
+	for _, hook := range hooks {
+		if err := hook(tx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package lifecycle
+
+import (
+	"context"
+	"log"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/realtime"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// DefaultInterval is how often the scheduler looks for due events
+	DefaultInterval = time.Minute
+	// DefaultEventDuration is how long after its start an event becomes past
+	DefaultEventDuration = 4 * time.Hour
+
+	// schedulerLockKey is the Postgres advisory lock that makes only one
+	// instance run a scheduler pass at a time
+	schedulerLockKey int64 = 0x73706f746c6967 // "spotlig"
+	// batchSize caps how many events of each kind one pass handles
+	batchSize = 100
+)
+
+// Scheduler periodically closes bidding and moves events to ongoing and
+// past. Any number of API instances may run one; an advisory lock makes
+// each pass run on a single instance.
+type Scheduler struct {
+	db     *gorm.DB
+	engine *Engine
+	hub    *realtime.Hub
+
+	Interval      time.Duration
+	EventDuration time.Duration
+}
+
+// NewScheduler returns a scheduler using the default interval and event
+// duration
+func NewScheduler(db *gorm.DB, engine *Engine, hub *realtime.Hub) *Scheduler {
+	return &Scheduler{
+		db:            db,
+		engine:        engine,
+		hub:           hub,
+		Interval:      DefaultInterval,
+		EventDuration: DefaultEventDuration,
+	}
+}
+
+// Start runs a pass immediately and then every Interval until ctx is done
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.Interval)
+		defer ticker.Stop()
+		for {
+			if err := s.RunOnce(ctx); err != nil {
+				log.Printf("Event scheduler pass failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce performs a single pass if no other instance is running one. The
+// advisory lock is held by a transaction that stays open for the pass and
+// is released when it ends, even if this process dies.
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	db := s.db.WithContext(ctx)
+	return db.Transaction(func(lockTx *gorm.DB) error {
+		var acquired bool
+		if err := lockTx.Raw("SELECT pg_try_advisory_xact_lock(?)", schedulerLockKey).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if !acquired {
+			return nil
+		}
+
+		now := time.Now()
+		s.advance(db, "close bidding",
+			db.Where("bidding_closed_at IS NULL AND bid_deadline <= ? AND status IN ?",
+				now, []models.EventStatus{models.EventStatusUpcoming, models.EventStatusOngoing}),
+			func(tx *gorm.DB, event *models.Event) error {
+				if event.BiddingClosedAt != nil || event.BidDeadline.After(now) {
+					return nil
+				}
+				return s.engine.CloseBidding(tx, event)
+			})
+
+		s.advance(db, "start",
+			db.Where("status = ? AND date <= ?", models.EventStatusUpcoming, now),
+			func(tx *gorm.DB, event *models.Event) error {
+				if event.Status != models.EventStatusUpcoming || event.Date.After(now) {
+					return nil
+				}
+				// Bidding never stays open once an event has begun
+				if err := s.engine.CloseBidding(tx, event); err != nil {
+					return err
+				}
+				return s.engine.Transition(tx, event, models.EventStatusOngoing)
+			})
+
+		finishedBefore := now.Add(-s.EventDuration)
+		s.advance(db, "finish",
+			db.Where("status = ? AND date <= ?", models.EventStatusOngoing, finishedBefore),
+			func(tx *gorm.DB, event *models.Event) error {
+				if event.Status != models.EventStatusOngoing || event.Date.After(finishedBefore) {
+					return nil
+				}
+				return s.engine.Transition(tx, event, models.EventStatusPast)
+			})
+		return nil
+	})
+}
+
+// advance applies fn to each event matched by query, one transaction per
+// event with the event row locked. Conditions are rechecked by fn because
+// the event may have changed since it was selected.
+func (s *Scheduler) advance(db *gorm.DB, step string, query *gorm.DB, fn func(tx *gorm.DB, event *models.Event) error) {
+	var ids []string
+	if err := query.Model(&models.Event{}).Order("date ASC").Limit(batchSize).Pluck("id", &ids).Error; err != nil {
+		log.Printf("Event scheduler failed to select events to %s: %v", step, err)
+		return
+	}
+
+	for _, id := range ids {
+		err := s.hub.Transaction(db, func(tx *gorm.DB) error {
+			var event models.Event
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, "id = ?", id).Error; err != nil {
+				return err
+			}
+			return fn(tx, &event)
+		})
+		if err != nil && err != ErrInvalidTransition {
+			log.Printf("Event scheduler failed to %s event %s: %v", step, id, err)
+		}
+	}
+}
@@ -3,19 +3,20 @@ package middleware
 import (
 	"fmt"
 	"net/http"
-	"os"
-	"spotlight-backend-go/internal/database"
 	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/realtime"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware requires a valid access token signed with jwtSecret in the
+// Authorization header
+func AuthMiddleware(db *gorm.DB, jwtSecret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
-		fmt.Printf("Auth header: %s\n", authHeader) // Debug log
 
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
@@ -25,69 +26,151 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			fmt.Printf("Invalid auth format: %v\n", parts) // Debug log
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
 			c.Abort()
 			return
 		}
 
 		tokenString := parts[1]
-		fmt.Printf("Token string: %s\n", tokenString) // Debug log
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			jwtSecret := os.Getenv("JWT_SECRET")
-			if jwtSecret == "" {
-				panic("JWT_SECRET environment variable is not set")
-			}
-			fmt.Printf("JWT Secret: %s\n", jwtSecret) // Debug log
-			return []byte(jwtSecret), nil
-		})
+		authenticate(c, db, tokenString, jwtSecret)
+	}
+}
 
-		if err != nil {
-			fmt.Printf("Token parse error: %v\n", err) // Debug log
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+// StreamAuthMiddleware authenticates like AuthMiddleware but also accepts a
+// stream ticket from hub in the ticket query parameter, because browsers
+// cannot set headers on WebSocket and EventSource requests
+func StreamAuthMiddleware(db *gorm.DB, jwtSecret string, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+				c.Abort()
+				return
+			}
+			authenticate(c, db, parts[1], jwtSecret)
 			return
 		}
 
-		if !token.Valid {
-			fmt.Printf("Token is invalid\n") // Debug log
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		ticket := c.Query("ticket")
+		if ticket == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Stream ticket is required"})
 			c.Abort()
 			return
 		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
+		userID, sessionID, ok := hub.RedeemTicket(ticket)
 		if !ok {
-			fmt.Printf("Invalid claims type\n") // Debug log
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired stream ticket"})
 			c.Abort()
 			return
 		}
 
-		fmt.Printf("Claims: %v\n", claims) // Debug log
+		authorizeSession(c, db, userID, sessionID)
+	}
+}
 
-		userID, ok := claims["user_id"].(string)
-		if !ok {
-			fmt.Printf("Invalid user_id in claims\n") // Debug log
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user_id in token"})
-			c.Abort()
-			return
+// authenticate validates an access token and its session, loads the user
+// onto the context and continues the chain, or aborts with 401/403
+func authenticate(c *gin.Context, db *gorm.DB, tokenString string, jwtSecret string) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Only accept the HMAC tokens generateToken issues
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
 		}
+		return []byte(jwtSecret), nil
+	})
+
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+
+	if !token.Valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		c.Abort()
+		return
+	}
 
-		// Trim any whitespace from the user ID
-		userID = strings.TrimSpace(userID)
+	userID, ok := claims["user_id"].(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user_id in token"})
+		c.Abort()
+		return
+	}
+
+	// Trim any whitespace from the user ID
+	userID = strings.TrimSpace(userID)
+
+	// Reject tokens whose session was revoked or has expired
+	sessionID, ok := claims["sid"].(string)
+	if !ok || sessionID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid session in token"})
+		c.Abort()
+		return
+	}
+
+	authorizeSession(c, db, userID, sessionID)
+}
+
+// authorizeSession checks that the session is still active and the user is
+// not suspended, loads the user onto the context and continues the chain,
+// or aborts with 401/403
+func authorizeSession(c *gin.Context, db *gorm.DB, userID string, sessionID string) {
+	var session models.Session
+	if err := db.First(&session, "id = ? AND user_id = ?", sessionID, userID).Error; err != nil || !session.Active() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+		c.Abort()
+		return
+	}
+
+	var user models.User
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		c.Abort()
+		return
+	}
+
+	if user.IsSuspended() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account suspended"})
+		c.Abort()
+		return
+	}
+
+	c.Set("user", &user)
+	c.Set("user_id", user.ID)
+	c.Set("session_id", session.ID)
+	c.Next()
+}
 
-		var user models.User
-		if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
-			fmt.Printf("User not found: %v\n", err) // Debug log
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+// RequireRole allows the request through only if the user loaded by
+// AuthMiddleware has one of the given roles
+func RequireRole(roles ...models.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("user")
+		user, ok := value.(*models.User)
+		if !exists || !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 			c.Abort()
 			return
 		}
 
-		c.Set("user", &user)
-		c.Set("user_id", user.ID)
-		c.Next()
+		for _, role := range roles {
+			if user.Role == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		c.Abort()
 	}
 }
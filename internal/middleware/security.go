@@ -35,23 +35,26 @@ func CORSMiddleware() gin.HandlerFunc {
 			"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH",
 		},
 		AllowHeaders: []string{
-			"Origin", "Content-Type", "Authorization", "Accept", "X-Requested-With",
+			"Origin", "Content-Type", "Authorization", "Accept", "X-Requested-With", "X-Device-Name",
 		},
 		ExposeHeaders: []string{
 			"Content-Length", "Content-Type",
 		},
 		AllowCredentials: true,
-		AllowOriginFunc: func(origin string) bool {
-			// Accept all localhost origins for local dev flexibility
-			return origin == "http://localhost:3000" || 
-				origin == "http://localhost:8080" || 
-				origin == "https://spot.smartrating.in" ||
-				origin == "https://spotlight-backend-go.onrender.com"
-		},
+		AllowOriginFunc: IsAllowedOrigin,
 		MaxAge: 12 * time.Hour,
 	})
 }
 
+// IsAllowedOrigin reports whether browsers on origin may call the API
+func IsAllowedOrigin(origin string) bool {
+	// Accept all localhost origins for local dev flexibility
+	return origin == "http://localhost:3000" || 
+		origin == "http://localhost:8080" || 
+		origin == "https://spot.smartrating.in" ||
+		origin == "https://spotlight-backend-go.onrender.com"
+}
+
 // RateLimitMiddleware returns a rate limiting middleware
 func RateLimitMiddleware() gin.HandlerFunc {
 	// Create a rate limiter with a limit of 100 requests per minute
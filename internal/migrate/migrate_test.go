@@ -0,0 +1,105 @@
+package migrate
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func testMigrations(t *testing.T, fsys fstest.MapFS) []Migration {
+	t.Helper()
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return migrations
+}
+
+// recordsFor returns schema_migrations rows for the migrations as if they
+// had all been applied
+func recordsFor(migrations []Migration) map[int64]applied {
+	records := make(map[int64]applied, len(migrations))
+	for _, m := range migrations {
+		records[m.Version] = applied{name: m.Name, checksum: m.Checksum}
+	}
+	return records
+}
+
+func TestLoad(t *testing.T) {
+	migrations := testMigrations(t, fstest.MapFS{
+		"0002_add_bio.up.sql":   {Data: []byte("ALTER TABLE users ADD bio TEXT;\n")},
+		"0002_add_bio.down.sql": {Data: []byte("ALTER TABLE users DROP bio;\n")},
+		"0001_initial.up.sql":   {Data: []byte("CREATE TABLE users (id TEXT);\n")},
+		"README.md":             {Data: []byte("not a migration")},
+	})
+
+	if len(migrations) != 2 {
+		t.Fatalf("loaded %d migrations, want 2", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Fatalf("migrations not ordered by version: %d, %d", migrations[0].Version, migrations[1].Version)
+	}
+	if migrations[1].Name != "add_bio" || migrations[1].Down == "" {
+		t.Fatalf("unexpected migration %+v", migrations[1])
+	}
+}
+
+func TestLoadWithoutUpFile(t *testing.T) {
+	_, err := Load(fstest.MapFS{
+		"0001_initial.down.sql": {Data: []byte("DROP TABLE users;\n")},
+	})
+	if err == nil {
+		t.Fatal("Load() accepted a migration without an up file")
+	}
+}
+
+func TestChecksumIgnoresLineEndings(t *testing.T) {
+	unix := testMigrations(t, fstest.MapFS{
+		"0001_initial.up.sql": {Data: []byte("CREATE TABLE users (\n    id TEXT\n);\n")},
+	})
+	windows := testMigrations(t, fstest.MapFS{
+		"0001_initial.up.sql": {Data: []byte("CREATE TABLE users (\r\n    id TEXT\r\n);\r\n")},
+	})
+	if unix[0].Checksum != windows[0].Checksum {
+		t.Fatal("CRLF line endings change the checksum")
+	}
+}
+
+func TestCheckApplied(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_initial.up.sql": {Data: []byte("CREATE TABLE users (id TEXT);\n")},
+		"0002_add_bio.up.sql": {Data: []byte("ALTER TABLE users ADD bio TEXT;\n")},
+	}
+	migrations := testMigrations(t, fsys)
+
+	t.Run("all applied and unchanged", func(t *testing.T) {
+		if err := checkApplied(migrations, recordsFor(migrations)); err != nil {
+			t.Fatalf("checkApplied() error = %v", err)
+		}
+	})
+
+	t.Run("some pending", func(t *testing.T) {
+		if err := checkApplied(migrations, recordsFor(migrations[:1])); err != nil {
+			t.Fatalf("checkApplied() error = %v", err)
+		}
+	})
+
+	t.Run("applied file edited", func(t *testing.T) {
+		records := recordsFor(migrations)
+		edited := testMigrations(t, fstest.MapFS{
+			"0001_initial.up.sql": {Data: []byte("CREATE TABLE users (id TEXT, name TEXT);\n")},
+			"0002_add_bio.up.sql": fsys["0002_add_bio.up.sql"],
+		})
+		err := checkApplied(edited, records)
+		if !errors.Is(err, ErrChecksumMismatch) {
+			t.Fatalf("checkApplied() error = %v, want %v", err, ErrChecksumMismatch)
+		}
+	})
+
+	t.Run("applied file removed", func(t *testing.T) {
+		err := checkApplied(migrations[:1], recordsFor(migrations))
+		if !errors.Is(err, ErrMissingMigration) {
+			t.Fatalf("checkApplied() error = %v, want %v", err, ErrMissingMigration)
+		}
+	})
+}
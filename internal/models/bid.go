@@ -4,14 +4,31 @@ import (
 	"time"
 )
 
-// Bid represents a bid placed on an event
+// BidStatus represents where a bid stands in a fan's revision history
+type BidStatus string
+
+const (
+	// BidStatusActive is the fan's current bid; there is at most one per
+	// fan per event
+	BidStatusActive BidStatus = "active"
+	// BidStatusSuperseded is an earlier amount replaced by a raise
+	BidStatusSuperseded BidStatus = "superseded"
+	// BidStatusWithdrawn is a bid the fan took back before the deadline
+	BidStatusWithdrawn BidStatus = "withdrawn"
+)
+
+// Bid is one revision of a fan's bid on an event. The application holds
+// the current amount; bids keep the full history. The one active bid per
+// fan per event is enforced by a partial unique index in the schema.
 type Bid struct {
-	ID        string    `json:"id" gorm:"primaryKey;type:char(36)"`
-	EventID   string    `json:"event_id" gorm:"type:char(36)"`
-	UserID    string    `json:"user_id" gorm:"type:char(36)"`
-	Amount    float64   `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	EventID       string    `json:"event_id" gorm:"type:char(36);index:idx_bids_event_user"`
+	UserID        string    `json:"user_id" gorm:"type:char(36);index:idx_bids_event_user"`
+	ApplicationID *string   `json:"application_id" gorm:"type:char(36);index"`
+	Amount        float64   `json:"amount"`
+	Status        BidStatus `json:"status" gorm:"type:varchar(16);not null;default:active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 
 	// Associations
 	Event *Event `json:"event,omitempty" gorm:"foreignKey:EventID"`
@@ -2,23 +2,59 @@ package models
 
 import (
 	"time"
+)
+
+// ChatRoomType distinguishes event group chats from one-to-one chats
+type ChatRoomType string
+
+const (
+	// ChatRoomTypeEvent is the group chat of an event's host and attendees
+	ChatRoomTypeEvent ChatRoomType = "event"
+	// ChatRoomTypeDirect is a private chat between two users
+	ChatRoomTypeDirect ChatRoomType = "direct"
+)
 
-	"gorm.io/gorm"
+// Chat room statuses
+const (
+	ChatRoomStatusActive   = "active"
+	ChatRoomStatusArchived = "archived"
 )
 
+// ChatRoom is a conversation. Event rooms are open to the event's host and
+// attendees; direct rooms to the two users in ChatRoomMember.
 type ChatRoom struct {
-	gorm.Model
-	EventID uint   `json:"event_id"`
-	Event   Event  `json:"event" gorm:"foreignKey:EventID"`
-	Status  string `json:"status" gorm:"default:'active'"`
+	ID      uint         `json:"id" gorm:"primaryKey"`
+	Type    ChatRoomType `json:"type" gorm:"type:varchar(20);not null;default:'event'"`
+	EventID *string      `json:"event_id" gorm:"type:char(36);uniqueIndex"`
+	Event   *Event       `json:"event,omitempty" gorm:"foreignKey:EventID"`
+	// DirectKey is the two user IDs of a direct room in sorted order, so each
+	// pair of users has at most one room
+	DirectKey *string   `json:"-" gorm:"type:varchar(73);uniqueIndex"`
+	Status    string    `json:"status" gorm:"default:'active'"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ChatRoomMember records a user's read position in a room. For direct rooms
+// the rows also define who is in the room; event room membership follows
+// the event, and rows are added the first time a member reads.
+type ChatRoomMember struct {
+	ChatRoomID        uint       `json:"chat_room_id" gorm:"primaryKey"`
+	UserID            string     `json:"user_id" gorm:"primaryKey;type:char(36)"`
+	LastReadMessageID uint       `json:"last_read_message_id" gorm:"not null;default:0"`
+	LastReadAt        *time.Time `json:"last_read_at"`
+	CreatedAt         time.Time  `json:"created_at"`
 }
 
+// Message is a message posted in a chat room. ReadAt is set in direct rooms
+// when the other user reads it.
 type Message struct {
-	gorm.Model
-	ChatRoomID uint       `json:"chat_room_id"`
-	ChatRoom   ChatRoom   `json:"chat_room" gorm:"foreignKey:ChatRoomID"`
-	SenderID   string     `json:"sender_id"`
-	Sender     User       `json:"sender" gorm:"foreignKey:SenderID;references:ID"`
-	Content    string     `json:"content"`
+	ID         uint       `json:"id" gorm:"primaryKey;index:idx_messages_room_id,priority:2"`
+	ChatRoomID uint       `json:"chat_room_id" gorm:"index:idx_messages_room_id,priority:1"`
+	SenderID   string     `json:"sender_id" gorm:"type:char(36)"`
+	Sender     *User      `json:"sender,omitempty" gorm:"foreignKey:SenderID;references:ID"`
+	Content    string     `json:"content" gorm:"type:text;not null"`
 	ReadAt     *time.Time `json:"read_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
 }
@@ -16,6 +16,54 @@ const (
 	EventStatusCancelled EventStatus = "cancelled"
 )
 
+// eventTransitions lists the states an event may move to from each state.
+// Past and cancelled events are final.
+var eventTransitions = map[EventStatus][]EventStatus{
+	EventStatusUpcoming: {EventStatusOngoing, EventStatusCancelled},
+	EventStatusOngoing:  {EventStatusPast, EventStatusCancelled},
+}
+
+// Valid reports whether s is a known event status
+func (s EventStatus) Valid() bool {
+	switch s {
+	case EventStatusUpcoming, EventStatusOngoing, EventStatusPast, EventStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo reports whether an event in this state may move to next
+func (s EventStatus) CanTransitionTo(next EventStatus) bool {
+	for _, allowed := range eventTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// AuctionMode decides how bids on an event compete
+type AuctionMode string
+
+const (
+	// AuctionModeSealed hides bids until bidding closes; the highest bids win
+	AuctionModeSealed AuctionMode = "sealed"
+	// AuctionModeAscending shows the current bid and each new bid must beat
+	// it by at least MinIncrement
+	AuctionModeAscending AuctionMode = "ascending"
+	// AuctionModeFixed sells places at MinBid to whoever bids first
+	AuctionModeFixed AuctionMode = "fixed"
+)
+
+// Valid reports whether m is a known auction mode
+func (m AuctionMode) Valid() bool {
+	switch m {
+	case AuctionModeSealed, AuctionModeAscending, AuctionModeFixed:
+		return true
+	}
+	return false
+}
+
 // ApplicationStatus represents the status of an event application
 type ApplicationStatus string
 
@@ -23,6 +71,9 @@ const (
 	ApplicationStatusPending  ApplicationStatus = "pending"
 	ApplicationStatusAccepted ApplicationStatus = "accepted"
 	ApplicationStatusRejected ApplicationStatus = "rejected"
+	// ApplicationStatusWithdrawn means the fan withdrew their bid; bidding
+	// again makes the application pending
+	ApplicationStatusWithdrawn ApplicationStatus = "withdrawn"
 )
 
 // Application represents a fan's application to attend an event
@@ -33,7 +84,9 @@ type Application struct {
 	BidAmount float64           `json:"bidAmount" bson:"bid_amount"`
 	Message   string            `json:"message" bson:"message"`
 	Status    ApplicationStatus `json:"status" bson:"status"`
-	CreatedAt time.Time         `json:"createdAt" bson:"created_at"`
+	// BidAt is when the current bid amount was placed and breaks ties
+	BidAt     time.Time `json:"bidAt" bson:"bid_at"`
+	CreatedAt time.Time `json:"createdAt" bson:"created_at"`
 }
 
 // Event represents an event hosted by an influencer
@@ -50,10 +103,21 @@ type Event struct {
 	Capacity    int            `json:"capacity"`
 	BidDeadline time.Time      `json:"bid_deadline"`
 	Status      EventStatus    `json:"status"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
+	AuctionMode AuctionMode    `json:"auction_mode" gorm:"type:varchar(16);not null;default:sealed"`
+	// MinIncrement is how much an ascending bid must beat CurrentBid by
+	MinIncrement float64 `json:"min_increment"`
+	// CurrentBid is the highest bid, live for ascending auctions and set when
+	// bidding closes for sealed ones
+	CurrentBid float64 `json:"current_bid"`
+	// WinnerID is the fan with the highest winning bid
+	WinnerID *string `json:"winner_id" gorm:"type:char(36)"`
+	// BiddingClosedAt is set by the scheduler once BidDeadline has passed
+	BiddingClosedAt *time.Time `json:"bidding_closed_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 
 	// Associations
 	Host      *User  `json:"host,omitempty" gorm:"foreignKey:HostID"`
+	Winner    *User  `json:"winner,omitempty" gorm:"foreignKey:WinnerID"`
 	Attendees []User `json:"attendees,omitempty" gorm:"many2many:event_attendees;"`
 }
@@ -0,0 +1,39 @@
+package models
+
+import "testing"
+
+func TestEventStatusCanTransitionTo(t *testing.T) {
+	statuses := []EventStatus{EventStatusUpcoming, EventStatusOngoing, EventStatusPast, EventStatusCancelled}
+	allowed := map[EventStatus]map[EventStatus]bool{
+		EventStatusUpcoming: {EventStatusOngoing: true, EventStatusCancelled: true},
+		EventStatusOngoing:  {EventStatusPast: true, EventStatusCancelled: true},
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := allowed[from][to]
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
+
+func TestEventStatusFinalStates(t *testing.T) {
+	for _, from := range []EventStatus{EventStatusPast, EventStatusCancelled} {
+		for _, to := range []EventStatus{EventStatusUpcoming, EventStatusOngoing, EventStatusPast, EventStatusCancelled} {
+			if from.CanTransitionTo(to) {
+				t.Errorf("%s is final but may move to %s", from, to)
+			}
+		}
+	}
+}
+
+func TestEventStatusUnknown(t *testing.T) {
+	if EventStatus("draft").CanTransitionTo(EventStatusOngoing) {
+		t.Error("unknown status may transition")
+	}
+	if EventStatusUpcoming.CanTransitionTo(EventStatus("draft")) {
+		t.Error("may transition to an unknown status")
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// Follow records that one user follows another. The followed user's
+// FollowerCount is kept in step with these rows.
+type Follow struct {
+	FollowerID  string    `json:"follower_id" gorm:"primaryKey;type:char(36)"`
+	FollowingID string    `json:"following_id" gorm:"primaryKey;type:char(36);index"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Associations
+	Follower  *User `json:"follower,omitempty" gorm:"foreignKey:FollowerID"`
+	Following *User `json:"following,omitempty" gorm:"foreignKey:FollowingID"`
+}
@@ -0,0 +1,25 @@
+package models
+
+// All returns a value of every model stored in the database
+func All() []interface{} {
+	return []interface{}{
+		&User{},
+		&Event{},
+		&EventAttendee{},
+		&Bid{},
+		&Application{},
+		&OTPCode{},
+		&Session{},
+		&VerificationRequest{},
+		&DocumentUpload{},
+		&Notification{},
+		&Transaction{},
+		&LedgerEntry{},
+		&PaymentOrder{},
+		&Withdrawal{},
+		&ChatRoom{},
+		&ChatRoomMember{},
+		&Message{},
+		&Follow{},
+	}
+}
@@ -8,25 +8,28 @@ import (
 type NotificationType string
 
 const (
-	NotificationTypeBidPlaced     NotificationType = "bid_placed"
-	NotificationTypeBidAccepted   NotificationType = "bid_accepted"
-	NotificationTypeBidRejected   NotificationType = "bid_rejected"
-	NotificationTypeEventReminder NotificationType = "event_reminder"
-	NotificationTypeNewFollower   NotificationType = "new_follower"
-	NotificationTypePayment       NotificationType = "payment"
+	NotificationTypeBidPlaced      NotificationType = "bid_placed"
+	NotificationTypeBidAccepted    NotificationType = "bid_accepted"
+	NotificationTypeBidRejected    NotificationType = "bid_rejected"
+	NotificationTypeEventReminder  NotificationType = "event_reminder"
+	NotificationTypeNewFollower    NotificationType = "new_follower"
+	NotificationTypePayment        NotificationType = "payment"
+	NotificationTypeVerification   NotificationType = "verification"
+	NotificationTypeBiddingClosed  NotificationType = "bidding_closed"
+	NotificationTypeEventCancelled NotificationType = "event_cancelled"
 )
 
 // Notification represents a notification for a user
 type Notification struct {
-	ID        string           `json:"id" bson:"_id,omitempty"`
-	UserID    string           `json:"userId" bson:"user_id"`
+	ID        string           `json:"id" bson:"_id,omitempty" gorm:"primaryKey;type:char(36)"`
+	UserID    string           `json:"userId" bson:"user_id" gorm:"type:char(36);index;not null"`
 	Type      NotificationType `json:"type" bson:"type"`
 	Title     string           `json:"title" bson:"title"`
 	Message   string           `json:"message" bson:"message"`
-	Read      bool             `json:"read" bson:"read"`
+	Read      bool             `json:"read" bson:"read" gorm:"default:false"`
 	CreatedAt time.Time        `json:"createdAt" bson:"created_at"`
 	// Optional fields for related entities
-	RelatedEventID *string `json:"relatedEventId,omitempty" bson:"related_event_id,omitempty"`
-	RelatedUserID  *string `json:"relatedUserId,omitempty" bson:"related_user_id,omitempty"`
-	RelatedBidID   *string `json:"relatedBidId,omitempty" bson:"related_bid_id,omitempty"`
+	RelatedEventID *string `json:"relatedEventId,omitempty" bson:"related_event_id,omitempty" gorm:"type:char(36)"`
+	RelatedUserID  *string `json:"relatedUserId,omitempty" bson:"related_user_id,omitempty" gorm:"type:char(36)"`
+	RelatedBidID   *string `json:"relatedBidId,omitempty" bson:"related_bid_id,omitempty" gorm:"type:char(36)"`
 }
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// OTPCode is a one-time login code sent to a mobile number. Only a hash of
+// the code is stored.
+type OTPCode struct {
+	ID           string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	MobileNumber string     `json:"mobile_number" gorm:"index;not null"`
+	CodeHash     string     `json:"-" gorm:"not null"`
+	Attempts     int        `json:"attempts" gorm:"default:0"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	ConsumedAt   *time.Time `json:"consumed_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for OTPCode
+func (OTPCode) TableName() string {
+	return "otp_codes"
+}
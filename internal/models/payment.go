@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+)
+
+// PaymentOrderStatus represents the state of a wallet top-up order
+type PaymentOrderStatus string
+
+const (
+	PaymentOrderStatusCreated PaymentOrderStatus = "created"
+	PaymentOrderStatusPaid    PaymentOrderStatus = "paid"
+	PaymentOrderStatusFailed  PaymentOrderStatus = "failed"
+)
+
+// PaymentOrder is a wallet top-up created with a payment provider. The wallet
+// is credited once, when the provider reports the payment as captured.
+type PaymentOrder struct {
+	ID                string             `json:"id" gorm:"primaryKey;type:char(36)"`
+	UserID            string             `json:"user_id" gorm:"type:char(36);index;not null"`
+	Provider          string             `json:"provider"`
+	ProviderOrderID   string             `json:"provider_order_id" gorm:"uniqueIndex;not null"`
+	ProviderPaymentID *string            `json:"provider_payment_id,omitempty" gorm:"uniqueIndex"`
+	Amount            float64            `json:"amount" gorm:"type:numeric(12,2)"`
+	Status            PaymentOrderStatus `json:"status" gorm:"default:'created'"`
+	TransactionID     *string            `json:"transaction_id,omitempty" gorm:"type:char(36)"`
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+}
+
+// WithdrawalStatus represents the state of a withdrawal request
+type WithdrawalStatus string
+
+const (
+	WithdrawalStatusRequested  WithdrawalStatus = "requested"
+	WithdrawalStatusProcessing WithdrawalStatus = "processing"
+	WithdrawalStatusCompleted  WithdrawalStatus = "completed"
+	WithdrawalStatusFailed     WithdrawalStatus = "failed"
+	WithdrawalStatusCancelled  WithdrawalStatus = "cancelled"
+)
+
+// withdrawalTransitions lists the states a withdrawal may move to from each state
+var withdrawalTransitions = map[WithdrawalStatus][]WithdrawalStatus{
+	WithdrawalStatusRequested:  {WithdrawalStatusProcessing, WithdrawalStatusFailed, WithdrawalStatusCancelled},
+	WithdrawalStatusProcessing: {WithdrawalStatusCompleted, WithdrawalStatusFailed},
+}
+
+// CanTransitionTo reports whether a withdrawal in this state may move to next
+func (s WithdrawalStatus) CanTransitionTo(next WithdrawalStatus) bool {
+	for _, allowed := range withdrawalTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Withdrawal is a request to pay out wallet funds. The amount is debited from
+// the wallet when requested and credited back if the payout fails or is
+// cancelled.
+type Withdrawal struct {
+	ID               string           `json:"id" gorm:"primaryKey;type:char(36)"`
+	UserID           string           `json:"user_id" gorm:"type:char(36);index;not null"`
+	Amount           float64          `json:"amount" gorm:"type:numeric(12,2)"`
+	Destination      string           `json:"destination"`
+	Provider         string           `json:"provider"`
+	ProviderPayoutID *string          `json:"provider_payout_id,omitempty" gorm:"uniqueIndex"`
+	Status           WithdrawalStatus `json:"status" gorm:"index;default:'requested'"`
+	FailureReason    string           `json:"failure_reason,omitempty"`
+	TransactionID    *string          `json:"transaction_id,omitempty" gorm:"type:char(36)"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+}
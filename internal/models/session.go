@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// Session represents a logged-in device. Access tokens carry the session ID
+// and the refresh token is rotated on every use; only its hash is stored.
+type Session struct {
+	ID               string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	UserID           string     `json:"user_id" gorm:"type:char(36);index;not null"`
+	RefreshTokenHash string     `json:"-" gorm:"not null"`
+	DeviceName       string     `json:"device_name"`
+	UserAgent        string     `json:"user_agent"`
+	IPAddress        string     `json:"ip_address"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	LastUsedAt       time.Time  `json:"last_used_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason    string     `json:"revoked_reason,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// Associations
+	User *User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// Active reports whether the session can still be used
+func (s *Session) Active() bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(time.Now())
+}
@@ -8,21 +8,40 @@ import (
 type TransactionType string
 
 const (
-	TransactionTypeBidPlaced       TransactionType = "bid_placed"
-	TransactionTypeBidRejected     TransactionType = "bid_rejected"
-	TransactionTypePaymentReceived TransactionType = "payment_received"
-	TransactionTypeTopUp           TransactionType = "top_up"
-	TransactionTypeWithdrawal      TransactionType = "withdrawal"
+	TransactionTypeBidPlaced        TransactionType = "bid_placed"
+	TransactionTypeBidRejected      TransactionType = "bid_rejected"
+	TransactionTypePaymentReceived  TransactionType = "payment_received"
+	TransactionTypeTopUp            TransactionType = "top_up"
+	TransactionTypeWithdrawal       TransactionType = "withdrawal"
+	TransactionTypeWithdrawalRefund TransactionType = "withdrawal_refund"
+	TransactionTypeOpeningBalance   TransactionType = "opening_balance"
 )
 
-// Transaction represents a financial transaction
+// Transaction represents a financial transaction as seen by one user. The
+// money movement itself is recorded as balanced LedgerEntry rows.
 type Transaction struct {
-	ID             string          `json:"id" bson:"_id,omitempty"`
-	UserID         string          `json:"userId" bson:"user_id"`
+	ID             string          `json:"id" bson:"_id,omitempty" gorm:"primaryKey;type:char(36)"`
+	UserID         string          `json:"userId" bson:"user_id" gorm:"type:char(36);index;not null"`
 	Type           TransactionType `json:"type" bson:"type"`
-	Amount         float64         `json:"amount" bson:"amount"`
+	Amount         float64         `json:"amount" bson:"amount" gorm:"type:numeric(12,2)"`
+	BalanceAfter   float64         `json:"balanceAfter" bson:"balance_after" gorm:"type:numeric(12,2)"`
 	Description    string          `json:"description" bson:"description"`
 	CreatedAt      time.Time       `json:"createdAt" bson:"created_at"`
 	RelatedEventID string          `json:"relatedEventId,omitempty" bson:"related_event_id,omitempty"`
 	RelatedUserID  string          `json:"relatedUserId,omitempty" bson:"related_user_id,omitempty"`
+	RelatedBidID   string          `json:"relatedBidId,omitempty" bson:"related_bid_id,omitempty"`
+
+	// Associations
+	Entries []LedgerEntry `json:"entries,omitempty" gorm:"foreignKey:TransactionID"`
+}
+
+// LedgerEntry is one side of a double-entry posting. The entries of a
+// transaction always sum to zero; an account's balance is the sum of its
+// entries.
+type LedgerEntry struct {
+	ID            string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	TransactionID string    `json:"transaction_id" gorm:"type:char(36);index;not null"`
+	Account       string    `json:"account" gorm:"index;not null"`
+	Amount        float64   `json:"amount" gorm:"type:numeric(12,2);not null"`
+	CreatedAt     time.Time `json:"created_at"`
 }
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 // UserRole represents the type of user
@@ -12,6 +13,7 @@ type UserRole string
 const (
 	RoleFan        UserRole = "fan"
 	RoleInfluencer UserRole = "influencer"
+	RoleAdmin      UserRole = "admin"
 )
 
 // Gender represents the user's gender
@@ -85,11 +87,24 @@ type User struct {
 	// Events hosted count
 	EventsHostedCount int `json:"events_hosted_count" gorm:"default:0"`
 
+	// Moderation
+	SuspendedAt     *time.Time `json:"suspended_at,omitempty"`
+	SuspendedReason string     `json:"suspended_reason,omitempty"`
+
+	// DeletedAt marks an anonymized account. The row stays because the
+	// ledger and other users' records refer to it.
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
 	// Associations
 	HostedEvents   []Event `json:"hosted_events,omitempty" gorm:"foreignKey:HostID"`
 	AttendedEvents []Event `json:"attended_events,omitempty" gorm:"many2many:event_attendees;"`
 }
 
+// IsSuspended reports whether an admin has suspended the user
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
+}
+
 // EventAttendee represents the many-to-many relationship between users and events
 type EventAttendee struct {
 	UserID    string `gorm:"primaryKey;type:char(36)"`
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+)
+
+// VerificationStatus represents the state of an identity verification request
+type VerificationStatus string
+
+const (
+	VerificationStatusSubmitted   VerificationStatus = "submitted"
+	VerificationStatusUnderReview VerificationStatus = "under_review"
+	VerificationStatusApproved    VerificationStatus = "approved"
+	VerificationStatusRejected    VerificationStatus = "rejected"
+)
+
+// VerificationRequest is a user's submission of a government ID document
+// for review by an admin
+type VerificationRequest struct {
+	ID              string             `json:"id" gorm:"primaryKey;type:char(36)"`
+	UserID          string             `json:"user_id" gorm:"type:char(36);index;not null"`
+	DocumentType    string             `json:"document_type"`
+	DocumentURL     string             `json:"document_url" gorm:"not null"`
+	Status          VerificationStatus `json:"status" gorm:"index;default:'submitted'"`
+	RejectionReason string             `json:"rejection_reason,omitempty"`
+	ReviewerID      *string            `json:"reviewer_id,omitempty" gorm:"type:char(36)"`
+	ReviewedAt      *time.Time         `json:"reviewed_at,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+
+	// DocumentKey locates the document in the private store. Documents
+	// submitted before private storage only have a DocumentURL.
+	DocumentKey       string     `json:"-"`
+	DocumentDeletedAt *time.Time `json:"document_deleted_at,omitempty"`
+
+	// Associations
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// IsOpen reports whether the request is still awaiting a decision
+func (v *VerificationRequest) IsOpen() bool {
+	return v.Status == VerificationStatusSubmitted || v.Status == VerificationStatusUnderReview
+}
+
+// DocumentUpload is an identity document in the private store that has not
+// been submitted for verification yet. Uploads never submitted are deleted
+// after a while.
+type DocumentUpload struct {
+	Key       string    `json:"key" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"type:char(36);index;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
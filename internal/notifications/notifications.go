@@ -0,0 +1,206 @@
+package notifications
+
+import (
+	"fmt"
+	"spotlight-backend-go/internal/models"
+	"spotlight-backend-go/internal/realtime"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Cursor marks a position in a user's notification list, which is ordered
+// newest first by creation time and then ID
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Create stores a notification for a user. Pass a transaction handle to
+// make the notification part of a larger change; inside a
+// realtime.Hub.Transaction it is also pushed to the user once committed.
+func Create(db *gorm.DB, n *models.Notification) error {
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+	if err := db.Create(n).Error; err != nil {
+		return err
+	}
+	realtime.Enqueue(db, n.UserID, realtime.EventNotification, n)
+	return nil
+}
+
+// List returns up to limit notifications for a user older than the cursor,
+// newest first, and the cursor for the following page (nil on the last page)
+func List(db *gorm.DB, userID string, after *Cursor, limit int, unreadOnly bool) ([]models.Notification, *Cursor, error) {
+	query := db.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read = ?", false)
+	}
+	if after != nil {
+		query = query.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var items []models.Notification
+	if err := query.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&items).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var next *Cursor
+	if len(items) > limit {
+		items = items[:limit]
+		last := items[len(items)-1]
+		next = &Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	return items, next, nil
+}
+
+// UnreadCount returns how many unread notifications a user has
+func UnreadCount(db *gorm.DB, userID string) (int64, error) {
+	var count int64
+	err := db.Model(&models.Notification{}).
+		Where("user_id = ? AND read = ?", userID, false).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks one of a user's notifications as read
+func MarkRead(db *gorm.DB, userID string, id string) error {
+	result := db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks all of a user's notifications as read and returns how
+// many changed
+func MarkAllRead(db *gorm.DB, userID string) (int64, error) {
+	result := db.Model(&models.Notification{}).
+		Where("user_id = ? AND read = ?", userID, false).
+		Update("read", true)
+	return result.RowsAffected, result.Error
+}
+
+// Delete removes one of a user's notifications
+func Delete(db *gorm.DB, userID string, id string) error {
+	result := db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Notification{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// BidPlaced tells a host that a fan bid on their event
+func BidPlaced(db *gorm.DB, hostID string, fan *models.User, event *models.Event, bidID string, amount float64) error {
+	return Create(db, &models.Notification{
+		UserID:         hostID,
+		Type:           models.NotificationTypeBidPlaced,
+		Title:          "New bid on " + event.Title,
+		Message:        fmt.Sprintf("%s bid %.2f on your event.", fan.Name, amount),
+		RelatedEventID: &event.ID,
+		RelatedUserID:  &fan.ID,
+		RelatedBidID:   &bidID,
+	})
+}
+
+// ApplicationDecided tells a fan whether their bid was accepted, either by
+// the host or by the auction closing
+func ApplicationDecided(db *gorm.DB, application *models.Application, event *models.Event) error {
+	n := &models.Notification{
+		UserID:         application.FanID,
+		RelatedEventID: &event.ID,
+		RelatedUserID:  &event.HostID,
+	}
+	if application.Status == models.ApplicationStatusAccepted {
+		n.Type = models.NotificationTypeBidAccepted
+		n.Title = "You're going to " + event.Title
+		n.Message = "Your bid was accepted."
+	} else {
+		n.Type = models.NotificationTypeBidRejected
+		n.Title = "Bid not accepted"
+		n.Message = "Your bid for " + event.Title + " was not accepted. Your funds have been returned to your wallet."
+	}
+	return Create(db, n)
+}
+
+// BiddingClosed tells a host that bidding on their event has closed, with
+// how many bids won and how many still wait for a decision
+func BiddingClosed(db *gorm.DB, event *models.Event) error {
+	var counts struct {
+		Accepted int64
+		Pending  int64
+	}
+	if err := db.Model(&models.Application{}).
+		Select("COUNT(*) FILTER (WHERE status = ?) AS accepted, COUNT(*) FILTER (WHERE status = ?) AS pending",
+			models.ApplicationStatusAccepted, models.ApplicationStatusPending).
+		Where("event_id = ?", event.ID).
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("%d bids won a place.", counts.Accepted)
+	if counts.Pending > 0 {
+		message = fmt.Sprintf("%d pending bids are waiting for your decision.", counts.Pending)
+	}
+	return Create(db, &models.Notification{
+		UserID:         event.HostID,
+		Type:           models.NotificationTypeBiddingClosed,
+		Title:          "Bidding closed for " + event.Title,
+		Message:        message,
+		RelatedEventID: &event.ID,
+	})
+}
+
+// NewFollower tells a user that someone started following them
+func NewFollower(db *gorm.DB, userID string, follower *models.User) error {
+	return Create(db, &models.Notification{
+		UserID:        userID,
+		Type:          models.NotificationTypeNewFollower,
+		Title:         "New follower",
+		Message:       follower.Name + " started following you.",
+		RelatedUserID: &follower.ID,
+	})
+}
+
+// EventCancelled tells an applicant or attendee that an event was cancelled
+// and how much was returned to their wallet
+func EventCancelled(db *gorm.DB, userID string, event *models.Event, reason string, refunded float64) error {
+	message := "The event has been cancelled."
+	if reason != "" {
+		message = "The event has been cancelled: " + reason
+	}
+	if refunded > 0 {
+		message += fmt.Sprintf(" %.2f has been returned to your wallet.", refunded)
+	}
+	return Create(db, &models.Notification{
+		UserID:         userID,
+		Type:           models.NotificationTypeEventCancelled,
+		Title:          event.Title + " was cancelled",
+		Message:        message,
+		RelatedEventID: &event.ID,
+		RelatedUserID:  &event.HostID,
+	})
+}
+
+// Payment tells a user about money entering or leaving their wallet
+func Payment(db *gorm.DB, userID string, title string, message string) error {
+	return Create(db, &models.Notification{
+		UserID:  userID,
+		Type:    models.NotificationTypePayment,
+		Title:   title,
+		Message: message,
+	})
+}
@@ -0,0 +1,90 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// FakeProvider is an in-process PaymentProvider for local development and
+// tests. It never moves real money; use SignWebhook to produce the webhook
+// calls a real provider would send.
+type FakeProvider struct {
+	secret []byte
+
+	mu      sync.Mutex
+	orders  map[string]Order
+	payouts map[string]Payout
+}
+
+// NewFakeProvider returns a fake provider that signs webhooks with secret
+func NewFakeProvider(secret string) *FakeProvider {
+	return &FakeProvider{
+		secret:  []byte(secret),
+		orders:  make(map[string]Order),
+		payouts: make(map[string]Payout),
+	}
+}
+
+// Name returns "fake"
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+// CreateOrder records an order in memory
+func (p *FakeProvider) CreateOrder(ctx context.Context, amount float64, reference string) (*Order, error) {
+	order := Order{
+		ID:       "order_" + uuid.New().String(),
+		Amount:   amount,
+		Currency: "INR",
+	}
+	p.mu.Lock()
+	p.orders[order.ID] = order
+	p.mu.Unlock()
+	return &order, nil
+}
+
+// VerifyWebhook checks the hex HMAC-SHA256 signature of the payload
+func (p *FakeProvider) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	expected := p.sign(payload)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, ErrInvalidSignature
+	}
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// InitiatePayout records a payout in memory in the "processing" state
+func (p *FakeProvider) InitiatePayout(ctx context.Context, amount float64, destination string, reference string) (*Payout, error) {
+	payout := Payout{
+		ID:     "payout_" + uuid.New().String(),
+		Status: "processing",
+	}
+	p.mu.Lock()
+	p.payouts[payout.ID] = payout
+	p.mu.Unlock()
+	return &payout, nil
+}
+
+// SignWebhook serializes an event and signs it the way VerifyWebhook expects
+func (p *FakeProvider) SignWebhook(event WebhookEvent) ([]byte, string, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, p.sign(payload), nil
+}
+
+func (p *FakeProvider) sign(payload []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,56 @@
+package payments
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrInvalidSignature is returned when a webhook payload fails verification
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Webhook event types understood by the wallet
+const (
+	EventPaymentCaptured = "payment.captured"
+	EventPaymentFailed   = "payment.failed"
+	EventPayoutProcessed = "payout.processed"
+	EventPayoutFailed    = "payout.failed"
+)
+
+// Order is a checkout created with the provider for a wallet top-up
+type Order struct {
+	ID          string  `json:"id"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+	CheckoutURL string  `json:"checkout_url,omitempty"`
+}
+
+// Payout is a transfer from the platform to a user's bank account or UPI ID
+type Payout struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// WebhookEvent is a verified notification from the provider
+type WebhookEvent struct {
+	Type      string  `json:"type"`
+	OrderID   string  `json:"order_id,omitempty"`
+	PaymentID string  `json:"payment_id,omitempty"`
+	PayoutID  string  `json:"payout_id,omitempty"`
+	Amount    float64 `json:"amount"`
+	Reason    string  `json:"reason,omitempty"`
+	// Reference is the reference passed when the order or payout was
+	// created: our order or withdrawal ID
+	Reference string `json:"reference,omitempty"`
+}
+
+// PaymentProvider moves money between the platform and the outside world
+type PaymentProvider interface {
+	// Name identifies the provider in stored orders and payouts
+	Name() string
+	// CreateOrder starts a payment of amount; reference is our order ID
+	CreateOrder(ctx context.Context, amount float64, reference string) (*Order, error)
+	// VerifyWebhook checks the signature of a webhook body and parses it
+	VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error)
+	// InitiatePayout sends amount to destination; reference is our withdrawal ID
+	InitiatePayout(ctx context.Context, amount float64, destination string, reference string) (*Payout, error)
+}
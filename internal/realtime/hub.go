@@ -0,0 +1,247 @@
+package realtime
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Event types pushed to connected clients
+const (
+	EventMessage      = "message"
+	EventReadReceipt  = "read_receipt"
+	EventNotification = "notification"
+	EventBidUpdate    = "bid_update"
+	// EventAuctionUpdate carries a new current bid or deadline to the other
+	// bidders of an ascending auction
+	EventAuctionUpdate = "auction_update"
+	// EventResync tells a reconnecting client that events were missed and it
+	// should refetch its state over the REST API
+	EventResync = "resync"
+)
+
+const (
+	// historySize is how many recent events are kept per user for replay
+	historySize = 100
+	// historyTTL is how long an event stays available for replay. A client
+	// that was away for longer is told to resync.
+	historyTTL = 10 * time.Minute
+	// pruneInterval is how often expired history and tickets are dropped
+	pruneInterval = time.Minute
+	// bufferSize is how many undelivered events a connection may queue before
+	// it is dropped as too slow
+	bufferSize = 64
+)
+
+// Event is a single message delivered to a user's connections
+type Event struct {
+	ID        uint64      `json:"id"`
+	Type      string      `json:"type"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Subscription receives the events published to one user. C is closed when
+// the subscription ends, either by Unsubscribe, because the client fell too
+// far behind or because its session was revoked.
+type Subscription struct {
+	UserID    string
+	SessionID string
+	C         chan Event
+	// Revoked is set before C is closed by Disconnect
+	Revoked bool
+}
+
+// Hub is an in-process pub/sub keyed by user ID. Every event gets an ID
+// that increases across the process, so clients can reconnect with the last
+// ID they saw and receive what they missed from a short per-user history.
+type Hub struct {
+	mu      sync.Mutex
+	firstID uint64
+	lastID  uint64
+	// prunedID is the newest event dropped from history for its age
+	prunedID    uint64
+	subscribers map[string]map[*Subscription]struct{}
+	history     map[string][]Event
+	tickets     map[string]streamTicket
+}
+
+// NewHub returns an empty hub. Event IDs start from the current time in
+// microseconds so IDs handed out before a restart are older than new ones.
+func NewHub() *Hub {
+	start := uint64(time.Now().UnixMicro())
+	return &Hub{
+		firstID:     start + 1,
+		lastID:      start,
+		subscribers: make(map[string]map[*Subscription]struct{}),
+		history:     make(map[string][]Event),
+		tickets:     make(map[string]streamTicket),
+	}
+}
+
+// Start drops expired history and stream tickets in the background until
+// ctx is done
+func (h *Hub) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				h.prune(now)
+			}
+		}
+	}()
+}
+
+// prune drops history older than historyTTL, forgetting users with nothing
+// left to replay, and tickets that were never redeemed
+func (h *Hub) prune(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cutoff := now.Add(-historyTTL)
+	for userID, history := range h.history {
+		kept := 0
+		for kept < len(history) && history[kept].CreatedAt.Before(cutoff) {
+			kept++
+		}
+		if kept == 0 {
+			continue
+		}
+		if id := history[kept-1].ID; id > h.prunedID {
+			h.prunedID = id
+		}
+		if kept == len(history) {
+			delete(h.history, userID)
+		} else {
+			h.history[userID] = append([]Event(nil), history[kept:]...)
+		}
+	}
+
+	for value, ticket := range h.tickets {
+		if !now.Before(ticket.expiresAt) {
+			delete(h.tickets, value)
+		}
+	}
+}
+
+// Publish sends an event to every connection of a user and records it for
+// replay
+func (h *Hub) Publish(userID string, eventType string, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	event := Event{
+		ID:        h.lastID,
+		Type:      eventType,
+		Data:      data,
+		CreatedAt: time.Now(),
+	}
+
+	history := append(h.history[userID], event)
+	if len(history) > historySize {
+		history = history[len(history)-historySize:]
+	}
+	h.history[userID] = history
+
+	for sub := range h.subscribers[userID] {
+		select {
+		case sub.C <- event:
+		default:
+			// The client will reconnect and catch up from history
+			h.removeLocked(sub)
+		}
+	}
+}
+
+// Subscribe registers a connection for a user's session. If lastEventID is
+// non-zero the events published after it are returned for replay; when some
+// of them are no longer in the history a single resync event is returned
+// instead.
+func (h *Hub) Subscribe(userID string, sessionID string, lastEventID uint64) (*Subscription, []Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &Subscription{UserID: userID, SessionID: sessionID, C: make(chan Event, bufferSize)}
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[userID][sub] = struct{}{}
+
+	if lastEventID == 0 {
+		return sub, nil
+	}
+
+	history := h.history[userID]
+	var missed []Event
+	for _, event := range history {
+		if event.ID > lastEventID {
+			missed = append(missed, event)
+		}
+	}
+
+	// The client's position is before anything this hub can replay, either
+	// because the history was trimmed or expired or because the server
+	// restarted
+	trimmed := len(history) == historySize && history[0].ID > lastEventID+1
+	expired := lastEventID < h.prunedID && (len(history) == 0 || history[0].ID > lastEventID+1)
+	if lastEventID < h.firstID-1 || trimmed || expired {
+		return sub, []Event{{
+			ID:        h.lastID,
+			Type:      EventResync,
+			CreatedAt: time.Now(),
+		}}
+	}
+	return sub, missed
+}
+
+// Unsubscribe removes a connection and closes its channel
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(sub)
+}
+
+// Disconnect closes a user's connections that belong to one of the given
+// sessions, or all of them when no session is given. It is called when
+// sessions are revoked so their streams don't outlive them.
+func (h *Hub) Disconnect(userID string, sessionIDs ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers[userID] {
+		if len(sessionIDs) > 0 && !containsString(sessionIDs, sub.SessionID) {
+			continue
+		}
+		sub.Revoked = true
+		h.removeLocked(sub)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Hub) removeLocked(sub *Subscription) {
+	subs, ok := h.subscribers[sub.UserID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	close(sub.C)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.UserID)
+	}
+}
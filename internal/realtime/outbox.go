@@ -0,0 +1,49 @@
+package realtime
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+type outboxKey struct{}
+
+type pendingEvent struct {
+	userID    string
+	eventType string
+	data      interface{}
+}
+
+// outbox holds the events queued during a transaction
+type outbox struct {
+	mu     sync.Mutex
+	events []pendingEvent
+}
+
+// Transaction runs fn in a database transaction and publishes the events
+// queued with Enqueue only once it commits, so clients never hear about
+// changes that were rolled back
+func (h *Hub) Transaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	out := &outbox{}
+	ctx := context.WithValue(db.Statement.Context, outboxKey{}, out)
+	if err := db.WithContext(ctx).Transaction(fn); err != nil {
+		return err
+	}
+	for _, e := range out.events {
+		h.Publish(e.userID, e.eventType, e.data)
+	}
+	return nil
+}
+
+// Enqueue queues an event for a user on the transaction started by
+// Hub.Transaction. It does nothing for handles outside such a transaction.
+func Enqueue(tx *gorm.DB, userID string, eventType string, data interface{}) {
+	out, ok := tx.Statement.Context.Value(outboxKey{}).(*outbox)
+	if !ok {
+		return
+	}
+	out.mu.Lock()
+	out.events = append(out.events, pendingEvent{userID: userID, eventType: eventType, data: data})
+	out.mu.Unlock()
+}
@@ -0,0 +1,56 @@
+package realtime
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+)
+
+// ticketTTL is how long a stream ticket can be redeemed after it is issued
+const ticketTTL = 30 * time.Second
+
+// streamTicket is an issued, not yet redeemed ticket
+type streamTicket struct {
+	userID    string
+	sessionID string
+	expiresAt time.Time
+}
+
+// IssueTicket returns a single-use ticket that authenticates one stream
+// connection for a session. Browsers cannot set headers on WebSocket and
+// EventSource requests, so the ticket goes in the URL instead of the access
+// token; it expires quickly and is useless once redeemed, so request logs
+// don't leak anything that still works.
+func (h *Hub) IssueTicket(userID string, sessionID string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	value := base64.RawURLEncoding.EncodeToString(b)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tickets[value] = streamTicket{
+		userID:    userID,
+		sessionID: sessionID,
+		expiresAt: time.Now().Add(ticketTTL),
+	}
+	return value, nil
+}
+
+// RedeemTicket consumes a ticket and returns the user and session it was
+// issued for. ok is false if the ticket is unknown, used or expired.
+func (h *Hub) RedeemTicket(value string) (userID string, sessionID string, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ticket, found := h.tickets[value]
+	if !found {
+		return "", "", false
+	}
+	delete(h.tickets, value)
+	if !time.Now().Before(ticket.expiresAt) {
+		return "", "", false
+	}
+	return ticket.userID, ticket.sessionID, true
+}
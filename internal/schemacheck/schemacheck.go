@@ -0,0 +1,200 @@
+// Package schemacheck compares the GORM models with the live database
+// schema. It reports the differences that break queries at run time:
+// missing tables and columns, incompatible column types and NULL handling
+// that disagrees with the model. Extra tables and columns in the database
+// are not reported.
+package schemacheck
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ProblemKind classifies a difference between a model and the database
+type ProblemKind string
+
+const (
+	MissingTable     ProblemKind = "missing_table"
+	MissingColumn    ProblemKind = "missing_column"
+	TypeMismatch     ProblemKind = "type_mismatch"
+	NullableMismatch ProblemKind = "nullable_mismatch"
+)
+
+// Problem is a difference between a model and the database
+type Problem struct {
+	Kind   ProblemKind
+	Table  string
+	Column string
+	Detail string
+}
+
+func (p Problem) String() string {
+	if p.Column == "" {
+		return fmt.Sprintf("%s: %s", p.Table, p.Detail)
+	}
+	return fmt.Sprintf("%s.%s: %s", p.Table, p.Column, p.Detail)
+}
+
+// column is a column as described by information_schema
+type column struct {
+	TableName              string
+	ColumnName             string
+	DataType               string
+	IsNullable             string
+	HasDefault             bool
+	CharacterMaximumLength *int
+}
+
+// sizedType matches a model type with a length, such as varchar(16)
+var sizedType = regexp.MustCompile(`^(varchar|char|character varying|character)\((\d+)\)`)
+
+// Check compares each model with the tables of the database's current
+// schema and returns the problems found, ordered by table and column
+func Check(db *gorm.DB, models ...interface{}) ([]Problem, error) {
+	var rows []column
+	if err := db.Raw(`SELECT table_name, column_name, data_type, is_nullable,
+		column_default IS NOT NULL OR is_identity = 'YES' OR is_generated = 'ALWAYS' AS has_default,
+		character_maximum_length
+		FROM information_schema.columns
+		WHERE table_schema = current_schema()`).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	tables := make(map[string]map[string]column)
+	for _, row := range rows {
+		if tables[row.TableName] == nil {
+			tables[row.TableName] = make(map[string]column)
+		}
+		tables[row.TableName][row.ColumnName] = row
+	}
+
+	var problems []Problem
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("parse model %T: %w", model, err)
+		}
+		table := stmt.Schema.Table
+
+		columns, ok := tables[table]
+		if !ok {
+			problems = append(problems, Problem{
+				Kind:   MissingTable,
+				Table:  table,
+				Detail: fmt.Sprintf("table for %s does not exist", stmt.Schema.Name),
+			})
+			continue
+		}
+
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || field.IgnoreMigration {
+				continue
+			}
+			col, ok := columns[field.DBName]
+			if !ok {
+				problems = append(problems, Problem{
+					Kind:   MissingColumn,
+					Table:  table,
+					Column: field.DBName,
+					Detail: fmt.Sprintf("column for %s.%s does not exist", stmt.Schema.Name, field.Name),
+				})
+				continue
+			}
+			modelType := modelDataType(db, field)
+			if problem := compareTypes(modelType, col); problem != "" {
+				problems = append(problems, Problem{Kind: TypeMismatch, Table: table, Column: field.DBName, Detail: problem})
+			}
+			if problem := compareNullable(field, col); problem != "" {
+				problems = append(problems, Problem{Kind: NullableMismatch, Table: table, Column: field.DBName, Detail: problem})
+			}
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Table != problems[j].Table {
+			return problems[i].Table < problems[j].Table
+		}
+		return problems[i].Column < problems[j].Column
+	})
+	return problems, nil
+}
+
+// modelDataType is the column type GORM would create for a field, without
+// constraints, e.g. "varchar(16)" or "timestamptz"
+func modelDataType(db *gorm.DB, field *schema.Field) string {
+	full := strings.ToLower(strings.TrimSpace(db.Migrator().FullDataTypeOf(field).SQL))
+	for _, constraint := range []string{" not null", " unique", " default ", " primary key"} {
+		if i := strings.Index(full, constraint); i >= 0 {
+			full = full[:i]
+		}
+	}
+	return strings.TrimSpace(full)
+}
+
+// compareTypes returns a description of the mismatch between a model type
+// and a column, or "" when they are compatible
+func compareTypes(modelType string, col column) string {
+	if typeFamily(modelType) != typeFamily(col.DataType) {
+		return fmt.Sprintf("type is %s in database, %s in model", col.DataType, modelType)
+	}
+	if match := sizedType.FindStringSubmatch(modelType); match != nil && col.CharacterMaximumLength != nil {
+		size, _ := strconv.Atoi(match[2])
+		if *col.CharacterMaximumLength < size {
+			return fmt.Sprintf("type is %s(%d) in database, %s in model", col.DataType, *col.CharacterMaximumLength, modelType)
+		}
+	}
+	return ""
+}
+
+// typeFamily groups types whose values convert into each other without
+// loss, so that e.g. char(36) and text or integer and bigint match
+func typeFamily(dataType string) string {
+	base := dataType
+	if i := strings.IndexByte(base, '('); i >= 0 {
+		base = base[:i]
+	}
+	switch strings.TrimSpace(base) {
+	case "text", "varchar", "char", "character", "character varying", "uuid":
+		return "text"
+	case "smallint", "integer", "int", "bigint", "serial", "bigserial", "smallserial":
+		return "integer"
+	case "numeric", "decimal", "real", "double precision":
+		return "numeric"
+	case "boolean", "bool":
+		return "boolean"
+	case "timestamp", "timestamptz", "timestamp without time zone", "timestamp with time zone", "date":
+		return "timestamp"
+	case "json", "jsonb":
+		return "json"
+	}
+	return base
+}
+
+// compareNullable returns a description of a NULL handling mismatch, or ""
+// when there is none. A NOT NULL model column must be NOT NULL in the
+// database, and a model field that can hold nil must be able to write it.
+func compareNullable(field *schema.Field, col column) string {
+	dbNullable := col.IsNullable == "YES"
+	if (field.NotNull || field.PrimaryKey) && dbNullable {
+		return "nullable in database, NOT NULL in model"
+	}
+	if !dbNullable && !col.HasDefault && canBeNil(field) && !field.PrimaryKey {
+		return "NOT NULL without a default in database, but the model can write NULL"
+	}
+	return ""
+}
+
+// canBeNil reports whether a field's Go type can hold nil
+func canBeNil(field *schema.Field) bool {
+	switch field.FieldType.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return false
+}
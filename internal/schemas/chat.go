@@ -2,29 +2,52 @@ package schemas
 
 import "time"
 
+// ChatRoomEvent summarizes the event an event room belongs to
+type ChatRoomEvent struct {
+	ID     string    `json:"id"`
+	Title  string    `json:"title"`
+	Date   time.Time `json:"date"`
+	Status string    `json:"status"`
+	HostID string    `json:"host_id"`
+}
+
 type ChatRoomResponse struct {
-	ID        uint          `json:"id"`
-	EventID   uint          `json:"event_id"`
-	Event     EventResponse `json:"event"`
-	Status    string        `json:"status"`
-	CreatedAt time.Time     `json:"created_at"`
-	UpdatedAt time.Time     `json:"updated_at"`
+	ID      uint           `json:"id"`
+	Type    string         `json:"type"`
+	EventID *string        `json:"event_id,omitempty"`
+	Event   *ChatRoomEvent `json:"event,omitempty"`
+	// Participant is the other user of a direct room
+	Participant *UserSummary     `json:"participant,omitempty"`
+	Status      string           `json:"status"`
+	LastMessage *MessageResponse `json:"last_message"`
+	UnreadCount int64            `json:"unread_count"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
 }
 
 type MessageResponse struct {
-	ID         uint         `json:"id"`
-	ChatRoomID uint         `json:"chat_room_id"`
-	SenderID   uint         `json:"sender_id"`
-	Sender     UserResponse `json:"sender"`
-	Content    string       `json:"content"`
-	ReadAt     *time.Time   `json:"read_at"`
-	CreatedAt  time.Time    `json:"created_at"`
+	ID         uint        `json:"id"`
+	ChatRoomID uint        `json:"chat_room_id"`
+	SenderID   string      `json:"sender_id"`
+	Sender     UserSummary `json:"sender"`
+	Content    string      `json:"content"`
+	ReadAt     *time.Time  `json:"read_at"`
+	CreatedAt  time.Time   `json:"created_at"`
 }
 
 type MessageCreate struct {
-	Content string `json:"content" binding:"required"`
+	Content string `json:"content" binding:"required,max=4000"`
 }
 
 type ChatRoomCreate struct {
-	EventID uint `json:"event_id" binding:"required"`
+	EventID string `json:"event_id" binding:"required"`
+}
+
+type DirectChatCreate struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+type ChatReadCreate struct {
+	// MessageID is the newest message read; omit to mark the whole room read
+	MessageID uint `json:"message_id"`
 }
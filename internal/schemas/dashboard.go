@@ -0,0 +1,81 @@
+package schemas
+
+import "time"
+
+// HostEventSummary is one row of the host dashboard's event list
+type HostEventSummary struct {
+	ID             string    `json:"id"`
+	Title          string    `json:"title"`
+	Date           time.Time `json:"date"`
+	Status         string    `json:"status"`
+	AuctionMode    string    `json:"auction_mode"`
+	Capacity       int       `json:"capacity"`
+	AttendeeCount  int64     `json:"attendee_count"`
+	ApplicantCount int64     `json:"applicant_count"`
+	HighestBid     float64   `json:"highest_bid"`
+	FillRate       float64   `json:"fill_rate"`
+}
+
+// BidStats summarizes the active bids on an event
+type BidStats struct {
+	Count   int64   `json:"count"`
+	Lowest  float64 `json:"lowest"`
+	Highest float64 `json:"highest"`
+	Average float64 `json:"average"`
+	Median  float64 `json:"median"`
+	P25     float64 `json:"p25"`
+	P75     float64 `json:"p75"`
+	P90     float64 `json:"p90"`
+}
+
+// BidBucket counts the bids in [From, To); the last bucket includes To
+type BidBucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int64   `json:"count"`
+}
+
+// EventStats is the host dashboard's view of one event
+type EventStats struct {
+	EventID        string      `json:"event_id"`
+	Title          string      `json:"title"`
+	Status         string      `json:"status"`
+	AuctionMode    string      `json:"auction_mode"`
+	Capacity       int         `json:"capacity"`
+	AttendeeCount  int64       `json:"attendee_count"`
+	FillRate       float64     `json:"fill_rate"`
+	ApplicantCount int64       `json:"applicant_count"`
+	PendingCount   int64       `json:"pending_count"`
+	AcceptedCount  int64       `json:"accepted_count"`
+	RejectedCount  int64       `json:"rejected_count"`
+	WithdrawnCount int64       `json:"withdrawn_count"`
+	BidRevisions   int64       `json:"bid_revisions"`
+	Bids           BidStats    `json:"bids"`
+	Distribution   []BidBucket `json:"distribution"`
+}
+
+// DailyBids counts the bids placed on one day
+type DailyBids struct {
+	Day        time.Time `json:"day"`
+	Bids       int64     `json:"bids"`
+	Bidders    int64     `json:"bidders"`
+	HighestBid float64   `json:"highest_bid"`
+}
+
+// EventEarnings is what a host earned from one event
+type EventEarnings struct {
+	EventID  string  `json:"event_id"`
+	Title    string  `json:"title"`
+	Gross    float64 `json:"gross"`
+	Refunded float64 `json:"refunded"`
+	Net      float64 `json:"net"`
+	Payments int64   `json:"payments"`
+}
+
+// EarningsSummary totals a host's earnings across events
+type EarningsSummary struct {
+	Gross    float64         `json:"gross"`
+	Refunded float64         `json:"refunded"`
+	Net      float64         `json:"net"`
+	Events   []EventEarnings `json:"events"`
+}
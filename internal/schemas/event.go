@@ -1,6 +1,10 @@
 package schemas
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
 
 type EventResponse struct {
 	ID          uint          `json:"id"`
@@ -18,27 +22,70 @@ type EventResponse struct {
 	Winner      *UserResponse `json:"winner,omitempty"`
 }
 
+// EventListItem is the projection returned by event listings. It carries
+// counts instead of the attendee list.
+type EventListItem struct {
+	ID               string         `json:"id"`
+	Title            string         `json:"title"`
+	Category         string         `json:"category"`
+	Date             time.Time      `json:"date"`
+	Location         string         `json:"location"`
+	Images           datatypes.JSON `json:"images"`
+	MinBid           float64        `json:"min_bid"`
+	Capacity         int            `json:"capacity"`
+	BidDeadline      time.Time      `json:"bid_deadline"`
+	Status           string         `json:"status"`
+	AuctionMode      string         `json:"auction_mode"`
+	CurrentBid       float64        `json:"current_bid"`
+	HostID           string         `json:"host_id"`
+	Host             *UserSummary   `json:"host,omitempty"`
+	AttendeeCount    int64          `json:"attendee_count"`
+	ApplicationCount int64          `json:"application_count"`
+}
+
+// FeedItem is an event in a user's feed with its rank score and the
+// reasons it was recommended
+type FeedItem struct {
+	EventListItem
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
 type EventCreate struct {
-	Title       string   `json:"title" binding:"required"`
-	Description string   `json:"description" binding:"required"`
-	Date        string   `json:"date" binding:"required"`
-	Location    string   `json:"location" binding:"required"`
-	Category    string   `json:"category" binding:"required"`
-	MinBid      float64  `json:"min_bid" binding:"required"`
-	Images      []string `json:"images"`
-	Attendees   []string `json:"attendees"`
-	Capacity    int      `json:"capacity" binding:"required"`
-	BidDeadline string   `json:"bid_deadline" binding:"required"`
+	Title       string     `json:"title" binding:"required"`
+	Description string     `json:"description" binding:"required"`
+	Date        string     `json:"date" binding:"required"`
+	Location    string     `json:"location" binding:"required"`
+	Category    string     `json:"category" binding:"required"`
+	MinBid      float64    `json:"min_bid" binding:"required"`
+	Images      []ImageRef `json:"images"`
+	Attendees   []string   `json:"attendees"`
+	Capacity    int        `json:"capacity" binding:"required"`
+	BidDeadline string     `json:"bid_deadline" binding:"required"`
+	// AuctionMode defaults to sealed
+	AuctionMode  string  `json:"auction_mode" binding:"omitempty,oneof=sealed ascending fixed"`
+	MinIncrement float64 `json:"min_increment" binding:"omitempty,gt=0"`
 }
 
 type EventUpdate struct {
-	Title       *string    `json:"title,omitempty"`
-	Description *string    `json:"description,omitempty"`
-	Date        *time.Time `json:"date,omitempty"`
-	Location    *string    `json:"location,omitempty"`
-	Category    *string    `json:"category,omitempty"`
-	MinBid      *float64   `json:"min_bid,omitempty"`
-	Status      *string    `json:"status,omitempty"`
-	Images      *[]string  `json:"images,omitempty"`
-	Attendees   *[]string  `json:"attendees,omitempty"`
+	Title       *string     `json:"title,omitempty"`
+	Description *string     `json:"description,omitempty"`
+	Date        *time.Time  `json:"date,omitempty"`
+	Location    *string     `json:"location,omitempty"`
+	Category    *string     `json:"category,omitempty"`
+	MinBid      *float64    `json:"min_bid,omitempty"`
+	Status      *string     `json:"status,omitempty"`
+	Images      *[]ImageRef `json:"images,omitempty"`
+	Attendees   *[]string   `json:"attendees,omitempty"`
+}
+
+// AuctionUpdate is pushed to bidders when an ascending auction moves
+type AuctionUpdate struct {
+	EventID     string    `json:"event_id"`
+	CurrentBid  float64   `json:"current_bid"`
+	BidDeadline time.Time `json:"bid_deadline"`
+}
+
+type EventCancel struct {
+	Reason string `json:"reason" binding:"max=500"`
 }
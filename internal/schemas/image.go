@@ -0,0 +1,51 @@
+package schemas
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ImageRef is an image in a gallery, profile photo list or event. Images
+// uploaded through /upload carry their variant URLs keyed by variant name;
+// older entries are plain URLs. An ImageRef accepts and produces either
+// form: a bare URL string when it has no variants, an object otherwise.
+type ImageRef struct {
+	URL      string            `json:"url"`
+	Variants map[string]string `json:"variants,omitempty"`
+}
+
+// Variant returns the URL of the named variant, falling back to URL
+func (r ImageRef) Variant(name string) string {
+	if url := r.Variants[name]; url != "" {
+		return url
+	}
+	return r.URL
+}
+
+// MarshalJSON writes plain URLs as strings
+func (r ImageRef) MarshalJSON() ([]byte, error) {
+	if len(r.Variants) == 0 {
+		return json.Marshal(r.URL)
+	}
+	type imageRef ImageRef
+	return json.Marshal(imageRef(r))
+}
+
+// UnmarshalJSON reads a URL string or a {url, variants} object
+func (r *ImageRef) UnmarshalJSON(data []byte) error {
+	var url string
+	if err := json.Unmarshal(data, &url); err == nil {
+		*r = ImageRef{URL: url}
+		return nil
+	}
+	type imageRef ImageRef
+	var ref imageRef
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return errors.New("image must be a URL or an object with url and variants")
+	}
+	if ref.URL == "" {
+		return errors.New("image url is required")
+	}
+	*r = ImageRef(ref)
+	return nil
+}
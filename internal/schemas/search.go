@@ -0,0 +1,35 @@
+package schemas
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// EventSearchResult is an event matched by /search. TitleHighlight and
+// Snippet are HTML-escaped with matches wrapped in <mark> tags.
+type EventSearchResult struct {
+	ID             string         `json:"id"`
+	Title          string         `json:"title"`
+	TitleHighlight string         `json:"title_highlight"`
+	Snippet        string         `json:"snippet"`
+	Category       string         `json:"category"`
+	Location       string         `json:"location"`
+	Date           time.Time      `json:"date"`
+	Status         string         `json:"status"`
+	Images         datatypes.JSON `json:"images"`
+	MinBid         float64        `json:"min_bid"`
+	Rank           float64        `json:"rank"`
+}
+
+// InfluencerSearchResult is an influencer matched by /search. NameHighlight
+// and Snippet are HTML-escaped with matches wrapped in <mark> tags.
+type InfluencerSearchResult struct {
+	UserSummary
+	NameHighlight   string  `json:"name_highlight"`
+	Snippet         string  `json:"snippet"`
+	InstagramHandle string  `json:"instagram_handle,omitempty"`
+	FollowerCount   int     `json:"follower_count"`
+	IsVerified      bool    `json:"is_verified"`
+	Rank            float64 `json:"rank"`
+}
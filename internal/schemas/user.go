@@ -2,6 +2,7 @@ package schemas
 
 import (
 	"spotlight-backend-go/internal/models"
+	"time"
 )
 
 type UserResponse struct {
@@ -10,8 +11,8 @@ type UserResponse struct {
 	Email             string                `json:"email"`
 	AvatarURL         string                `json:"avatar_url"`
 	Bio               string                `json:"bio"`
-	MediaGallery      []string              `json:"media_gallery"`
-	ProfilePhotos     []string              `json:"profile_photos"`
+	MediaGallery      []ImageRef            `json:"media_gallery"`
+	ProfilePhotos     []ImageRef            `json:"profile_photos"`
 	EventsAttended    []string              `json:"events_attended"`
 	EventsHosted      []string              `json:"events_hosted"`
 	EventsHostedCount int                   `json:"events_hosted_count"`
@@ -29,12 +30,27 @@ type UserResponse struct {
 	VerifiedAt        *string               `json:"verified_at,omitempty"`
 }
 
+// UserSummary is the small public profile embedded in lists, chats and feeds
+type UserSummary struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Username  string `json:"username"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// FollowUser is an entry in a follower or following list
+type FollowUser struct {
+	UserSummary
+	IsVerified bool      `json:"is_verified"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
 type UserUpdate struct {
 	Name            *string                `json:"name,omitempty"`
 	AvatarURL       *string                `json:"avatar_url,omitempty"`
 	Bio             *string                `json:"bio,omitempty"`
-	MediaGallery    *[]string              `json:"media_gallery,omitempty"`
-	ProfilePhotos   *[]string              `json:"profile_photos,omitempty"`
+	MediaGallery    *[]ImageRef            `json:"media_gallery,omitempty"`
+	ProfilePhotos   *[]ImageRef            `json:"profile_photos,omitempty"`
 	CoverPhotoURL   *string                `json:"cover_photo_url,omitempty"`
 	InstagramHandle *string                `json:"instagram_handle,omitempty"`
 	Age             *int                   `json:"age,omitempty"`
@@ -46,14 +62,13 @@ type UserUpdate struct {
 	EducationLevel  *models.EducationLevel `json:"education_level,omitempty"`
 	Drinking        *models.DrinkingStatus `json:"drinking,omitempty"`
 	Interests       *[]string              `json:"interests,omitempty"`
-	GovernmentIDURL *string                `json:"government_id_url,omitempty"`
 }
 
 type UserCreate struct {
 	Name            string                `json:"name" binding:"required"`
 	Email           string                `json:"email" binding:"required,email"`
 	Password        string                `json:"password" binding:"required,min=6"`
-	Role            models.UserRole       `json:"role" binding:"required"`
+	Role            models.UserRole       `json:"role" binding:"required,oneof=fan influencer"`
 	Bio             string                `json:"bio"`
 	Gender          models.Gender         `json:"gender"`
 	Age             int                   `json:"age" binding:"required,min=18"`
@@ -61,9 +76,11 @@ type UserCreate struct {
 	Education       string                `json:"education"`
 	Interests       []string              `json:"interests"`
 	AvatarURL       string                `json:"avatar_url"`
-	MediaGallery    []string              `json:"media_gallery"`
+	MediaGallery    []ImageRef            `json:"media_gallery"`
 	InstagramHandle string                `json:"instagram_handle"`
-	FollowerCount   int                   `json:"follower_count"`
+	// SignupToken is returned by OTP verification for an unregistered
+	// number and attaches that number to the new account
+	SignupToken     string                `json:"signup_token"`
 }
 
 type LoginRequest struct {
@@ -0,0 +1,75 @@
+package sms
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// SMSSender delivers text messages to a mobile number
+type SMSSender interface {
+	Send(ctx context.Context, to string, body string) error
+}
+
+// LogSender writes outgoing messages to the application log instead of
+// delivering them. Intended for local development.
+type LogSender struct{}
+
+// NewLogSender returns a sender that only logs messages
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send logs the message
+func (s *LogSender) Send(ctx context.Context, to string, body string) error {
+	log.Printf("SMS to %s: %s", to, body)
+	return nil
+}
+
+// Message is a message captured by MemorySender
+type Message struct {
+	To     string
+	Body   string
+	SentAt time.Time
+}
+
+// MemorySender keeps outgoing messages in memory so tests can inspect them
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemorySender returns an empty in-memory sender
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+// Send records the message
+func (s *MemorySender) Send(ctx context.Context, to string, body string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, Message{To: to, Body: body, SentAt: time.Now()})
+	return nil
+}
+
+// Messages returns a copy of all recorded messages
+func (s *MemorySender) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Message, len(s.messages))
+	copy(out, s.messages)
+	return out
+}
+
+// Last returns the most recent message sent to the given number
+func (s *MemorySender) Last(to string) (Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].To == to {
+			return s.messages[i], true
+		}
+	}
+	return Message{}, false
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSignature is returned when a signed local URL is forged or
+// has expired
+var ErrInvalidSignature = errors.New("invalid or expired signature")
+
+// LocalStore keeps blobs as files under a directory. Public blobs are
+// served by a static file handler at publicURL; signed URLs point at
+// signedURL, served by the store itself through ServeHTTP.
+type LocalStore struct {
+	root      string
+	publicURL string
+	signedURL string
+	secret    []byte
+}
+
+// NewLocalStore returns a store rooted at dir
+func NewLocalStore(dir string, publicURL string, signedURL string, secret string) *LocalStore {
+	return &LocalStore{
+		root:      dir,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		signedURL: strings.TrimSuffix(signedURL, "/"),
+		secret:    []byte(secret),
+	}
+}
+
+// Name returns "local"
+func (s *LocalStore) Name() string {
+	return "local"
+}
+
+// Root returns the directory the blobs are kept in
+func (s *LocalStore) Root() string {
+	return s.root
+}
+
+// PublicPath is the URL path public blobs are served under
+func (s *LocalStore) PublicPath() string {
+	return urlPath(s.publicURL)
+}
+
+// SignedPath is the URL path ServeHTTP must be mounted at
+func (s *LocalStore) SignedPath() string {
+	return urlPath(s.signedURL)
+}
+
+// Put writes the blob to a temporary file and renames it into place so
+// readers never see a partial file
+func (s *LocalStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get opens the blob's file
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete removes the blob's file
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// URL returns the blob's URL under the public prefix
+func (s *LocalStore) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+// SignedURL returns a URL under the signed prefix carrying the expiry time
+// and an HMAC of the key and expiry
+func (s *LocalStore) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	expiresAt := strconv.FormatInt(time.Now().Add(clampExpiry(expires)).Unix(), 10)
+	query := url.Values{
+		"expires":   {expiresAt},
+		"signature": {s.sign(key, expiresAt)},
+	}
+	return s.signedURL + "/" + key + "?" + query.Encode(), nil
+}
+
+// Verify checks the expiry and signature of a signed URL for key
+func (s *LocalStore) Verify(key string, expiresAt string, signature string) error {
+	expires, err := strconv.ParseInt(expiresAt, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(s.sign(key, expiresAt)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ServeHTTP serves a blob requested through a signed URL. Mount it with
+// the signed URL prefix stripped from the path.
+func (s *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, err := cleanKey(strings.TrimPrefix(r.URL.Path, "/"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	query := r.URL.Query()
+	if err := s.Verify(key, query.Get("expires"), query.Get("signature")); err != nil {
+		http.Error(w, "Invalid or expired link", http.StatusForbidden)
+		return
+	}
+
+	path, _ := s.path(key)
+	f, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// path maps a key to its file under the root
+func (s *LocalStore) path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// urlPath returns the path of a URL prefix that may be absolute
+func urlPath(prefix string) string {
+	if u, err := url.Parse(prefix); err == nil && u.Host != "" {
+		return strings.TrimSuffix(u.Path, "/")
+	}
+	return prefix
+}
+
+func (s *LocalStore) sign(key string, expiresAt string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expiresAt))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,175 @@
+// Package storage keeps uploaded files in a blob store: the local disk for
+// development or any S3-compatible object store in production.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when a blob does not exist
+	ErrNotFound = errors.New("blob not found")
+	// ErrInvalidKey is returned for keys that are empty or escape the store
+	ErrInvalidKey = errors.New("invalid blob key")
+
+	errNoSigningSecret = errors.New("local storage needs a signing secret")
+)
+
+// MaxSignedURLExpiry is the longest a signed URL may stay valid, the limit
+// S3 places on presigned URLs
+const MaxSignedURLExpiry = 7 * 24 * time.Hour
+
+// BlobStore stores blobs under slash separated keys such as
+// "images/2f1c.jpg"
+type BlobStore interface {
+	// Name identifies the backend in logs
+	Name() string
+	// Put stores size bytes read from body under key, replacing any blob
+	// already there
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get opens the blob under key; the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob under key. Deleting a missing blob is not an
+	// error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the permanent public URL of a publicly readable blob
+	URL(key string) string
+	// SignedURL returns a URL that grants read access to the blob until
+	// expires has passed
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// Config selects and configures a blob store
+type Config struct {
+	// Backend is "local" (the default) or "s3"
+	Backend string
+
+	// LocalDir is where the local store keeps files
+	LocalDir string
+	// LocalPublicURL is the URL prefix the local files are served under
+	LocalPublicURL string
+	// LocalSignedURL is the URL prefix of the handler serving signed URLs
+	LocalSignedURL string
+	// LocalPrivateDir is where the private local store keeps files. It must
+	// not be inside LocalDir, which is served publicly.
+	LocalPrivateDir string
+	// LocalPrivateSignedURL is the URL prefix of the handler serving signed
+	// URLs of private files
+	LocalPrivateSignedURL string
+	// SigningSecret signs local URLs. The local backend requires it, so
+	// signed URLs keep working across restarts and instances.
+	SigningSecret string
+
+	// S3Endpoint is the object store URL, e.g. https://s3.us-east-1.amazonaws.com
+	// or http://localhost:9000 for MinIO
+	S3Endpoint string
+	S3Region   string
+	S3Bucket   string
+	// S3PrivateBucket holds private blobs. It must not allow public reads.
+	S3PrivateBucket   string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	// S3PublicURL is the prefix of public blob URLs, such as a CDN in front
+	// of the bucket. It defaults to the bucket URL.
+	S3PublicURL string
+	// S3PathStyle puts the bucket in the path instead of the host name, as
+	// MinIO and most S3-compatible stores expect
+	S3PathStyle bool
+}
+
+// New returns the blob store selected by cfg.Backend
+func New(cfg Config) (BlobStore, error) {
+	switch cfg.Backend {
+	case "", "local":
+		if cfg.SigningSecret == "" {
+			return nil, errNoSigningSecret
+		}
+		return NewLocalStore(cfg.LocalDir, cfg.LocalPublicURL, cfg.LocalSignedURL, cfg.SigningSecret), nil
+	case "s3":
+		return NewS3Store(S3Options{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PublicURL:       cfg.S3PublicURL,
+			PathStyle:       cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// NewPrivate returns the blob store for private files such as identity
+// documents. Its blobs are never served publicly: they can only be read
+// through signed URLs, and the local backend keeps them outside the
+// public directory.
+func NewPrivate(cfg Config) (BlobStore, error) {
+	switch cfg.Backend {
+	case "", "local":
+		if isWithin(cfg.LocalPrivateDir, cfg.LocalDir) {
+			return nil, fmt.Errorf("private storage directory %q must not be inside the public directory %q", cfg.LocalPrivateDir, cfg.LocalDir)
+		}
+		if cfg.SigningSecret == "" {
+			return nil, errNoSigningSecret
+		}
+		// A distinct secret keeps URLs signed for one store from working
+		// on the other
+		return NewLocalStore(cfg.LocalPrivateDir, "", cfg.LocalPrivateSignedURL, "private:"+cfg.SigningSecret), nil
+	case "s3":
+		if cfg.S3PrivateBucket == "" || cfg.S3PrivateBucket == cfg.S3Bucket {
+			return nil, errors.New("s3 storage needs a private bucket separate from the public one")
+		}
+		return NewS3Store(S3Options{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3PrivateBucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// isWithin reports whether dir is parent or a directory below it
+func isWithin(dir string, parent string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	parent, err = filepath.Abs(parent)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(parent, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// cleanKey validates a key and returns it in canonical form
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	cleaned := path.Clean(key)
+	if cleaned != key || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
+
+// clampExpiry keeps a signed URL lifetime within (0, MaxSignedURLExpiry]
+func clampExpiry(expires time.Duration) time.Duration {
+	if expires <= 0 || expires > MaxSignedURLExpiry {
+		return MaxSignedURLExpiry
+	}
+	return expires
+}
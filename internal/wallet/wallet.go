@@ -0,0 +1,396 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"spotlight-backend-go/internal/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExternalAccount is the counterparty for money entering or leaving the
+// platform (top-ups, withdrawals, opening balances)
+const ExternalAccount = "external"
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient wallet balance")
+	ErrInvalidAmount     = errors.New("amount must be positive")
+)
+
+// WalletAccount is the ledger account holding a user's spendable balance
+func WalletAccount(userID string) string {
+	return "wallet:" + userID
+}
+
+// EscrowAccount is the ledger account holding a fan's bid on an event
+func EscrowAccount(userID string, eventID string) string {
+	return "escrow:" + userID + ":" + eventID
+}
+
+// Posting moves Amount into (positive) or out of (negative) an account
+type Posting struct {
+	Account string
+	Amount  float64
+}
+
+// Post records a transaction for txn.UserID together with its ledger
+// postings and refreshes the cached wallet balance of every user whose
+// wallet account was touched. Postings must sum to zero. Call it inside a
+// database transaction.
+func Post(tx *gorm.DB, txn *models.Transaction, postings []Posting) error {
+	if err := checkBalanced(postings); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if txn.ID == "" {
+		txn.ID = uuid.New().String()
+	}
+	txn.Amount = Round(txn.Amount)
+	txn.CreatedAt = now
+
+	var entries []models.LedgerEntry
+	var wallets []string
+	for _, p := range postings {
+		if p.Amount == 0 {
+			continue
+		}
+		entries = append(entries, models.LedgerEntry{
+			ID:            uuid.New().String(),
+			TransactionID: txn.ID,
+			Account:       p.Account,
+			Amount:        p.Amount,
+			CreatedAt:     now,
+		})
+		if userID, ok := strings.CutPrefix(p.Account, "wallet:"); ok {
+			wallets = append(wallets, userID)
+		}
+	}
+
+	if err := tx.Omit("Entries").Create(txn).Error; err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		if err := tx.Create(&entries).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, userID := range wallets {
+		if err := refreshBalance(tx, userID); err != nil {
+			return err
+		}
+	}
+
+	balance, err := Balance(tx, WalletAccount(txn.UserID))
+	if err != nil {
+		return err
+	}
+	txn.BalanceAfter = balance
+	return tx.Model(txn).Update("balance_after", balance).Error
+}
+
+// checkBalanced rounds the postings to cents and checks that they sum to
+// zero
+func checkBalanced(postings []Posting) error {
+	var sum float64
+	for i := range postings {
+		postings[i].Amount = Round(postings[i].Amount)
+		sum += postings[i].Amount
+	}
+	if math.Abs(sum) > 0.001 {
+		return fmt.Errorf("unbalanced postings: sum is %.2f", sum)
+	}
+	return nil
+}
+
+// holdPostings moves delta from a fan's wallet into escrow for an event; a
+// negative delta moves it back
+func holdPostings(fanID string, eventID string, delta float64) []Posting {
+	return []Posting{
+		{Account: WalletAccount(fanID), Amount: -delta},
+		{Account: EscrowAccount(fanID, eventID), Amount: delta},
+	}
+}
+
+// releasePostings returns what is held in escrow to the fan's wallet
+func releasePostings(fanID string, eventID string, held float64) []Posting {
+	return []Posting{
+		{Account: EscrowAccount(fanID, eventID), Amount: -held},
+		{Account: WalletAccount(fanID), Amount: held},
+	}
+}
+
+// capturePostings pays what is held in escrow to the host's wallet
+func capturePostings(fanID string, hostID string, eventID string, held float64) []Posting {
+	return []Posting{
+		{Account: EscrowAccount(fanID, eventID), Amount: -held},
+		{Account: WalletAccount(hostID), Amount: held},
+	}
+}
+
+// Balance returns the ledger balance of an account
+func Balance(db *gorm.DB, account string) (float64, error) {
+	var balance float64
+	err := db.Model(&models.LedgerEntry{}).
+		Where("account = ?", account).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&balance).Error
+	return Round(balance), err
+}
+
+// EscrowBalance returns the total a user currently has held in bid escrow
+func EscrowBalance(db *gorm.DB, userID string) (float64, error) {
+	var balance float64
+	err := db.Model(&models.LedgerEntry{}).
+		Where("account LIKE ?", "escrow:"+userID+":%").
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&balance).Error
+	return Round(balance), err
+}
+
+// HoldBid sets the amount held in escrow for a fan's bid on an event to
+// amount, moving only the difference to or from the fan's wallet
+func HoldBid(tx *gorm.DB, fanID string, eventID string, bidID string, amount float64) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+	if err := lockUser(tx, fanID); err != nil {
+		return err
+	}
+
+	held, err := Balance(tx, EscrowAccount(fanID, eventID))
+	if err != nil {
+		return err
+	}
+	delta := Round(amount - held)
+	if delta == 0 {
+		return nil
+	}
+
+	if delta > 0 {
+		available, err := Balance(tx, WalletAccount(fanID))
+		if err != nil {
+			return err
+		}
+		if available < delta {
+			return ErrInsufficientFunds
+		}
+	}
+
+	txn := &models.Transaction{
+		UserID:         fanID,
+		Type:           models.TransactionTypeBidPlaced,
+		Amount:         delta,
+		Description:    "Bid held in escrow",
+		RelatedEventID: eventID,
+		RelatedBidID:   bidID,
+	}
+	if delta < 0 {
+		txn.Type = models.TransactionTypeBidRejected
+		txn.Amount = -delta
+		txn.Description = "Bid lowered, difference released from escrow"
+	}
+	return Post(tx, txn, holdPostings(fanID, eventID, delta))
+}
+
+// ReleaseBid returns everything held in escrow for a fan's bid on an event
+// to the fan's wallet
+func ReleaseBid(tx *gorm.DB, fanID string, eventID string, description string) error {
+	if err := lockUser(tx, fanID); err != nil {
+		return err
+	}
+	held, err := Balance(tx, EscrowAccount(fanID, eventID))
+	if err != nil {
+		return err
+	}
+	if held <= 0 {
+		return nil
+	}
+
+	return Post(tx, &models.Transaction{
+		UserID:         fanID,
+		Type:           models.TransactionTypeBidRejected,
+		Amount:         held,
+		Description:    description,
+		RelatedEventID: eventID,
+	}, releasePostings(fanID, eventID, held))
+}
+
+// CaptureBid pays everything held in escrow for a fan's bid on an event to
+// the host's wallet
+func CaptureBid(tx *gorm.DB, fanID string, hostID string, eventID string, description string) error {
+	if err := lockUser(tx, fanID); err != nil {
+		return err
+	}
+	if err := lockUser(tx, hostID); err != nil {
+		return err
+	}
+	held, err := Balance(tx, EscrowAccount(fanID, eventID))
+	if err != nil {
+		return err
+	}
+	if held <= 0 {
+		return nil
+	}
+
+	return Post(tx, &models.Transaction{
+		UserID:         hostID,
+		Type:           models.TransactionTypePaymentReceived,
+		Amount:         held,
+		Description:    description,
+		RelatedEventID: eventID,
+		RelatedUserID:  fanID,
+	}, capturePostings(fanID, hostID, eventID, held))
+}
+
+// RefundCapturedBid returns to a fan what was paid to the host for their
+// bid on an event, net of earlier refunds. The host's wallet may go
+// negative if the money was already withdrawn; the shortfall is recovered
+// from the host's future earnings.
+func RefundCapturedBid(tx *gorm.DB, fanID string, hostID string, eventID string, description string) (float64, error) {
+	if err := lockUser(tx, fanID); err != nil {
+		return 0, err
+	}
+	if err := lockUser(tx, hostID); err != nil {
+		return 0, err
+	}
+
+	var paid float64
+	if err := tx.Table("ledger_entries AS le").
+		Joins("JOIN transactions t ON t.id = le.transaction_id").
+		Where("le.account = ? AND t.related_event_id = ? AND (t.user_id = ? OR t.related_user_id = ?)",
+			WalletAccount(hostID), eventID, fanID, fanID).
+		Select("COALESCE(SUM(le.amount), 0)").
+		Scan(&paid).Error; err != nil {
+		return 0, err
+	}
+	paid = Round(paid)
+	if paid <= 0 {
+		return 0, nil
+	}
+
+	err := Post(tx, &models.Transaction{
+		UserID:         fanID,
+		Type:           models.TransactionTypeBidRejected,
+		Amount:         paid,
+		Description:    description,
+		RelatedEventID: eventID,
+		RelatedUserID:  hostID,
+	}, []Posting{
+		{Account: WalletAccount(hostID), Amount: -paid},
+		{Account: WalletAccount(fanID), Amount: paid},
+	})
+	return paid, err
+}
+
+// Credit adds money from outside the platform to a user's wallet
+func Credit(tx *gorm.DB, userID string, amount float64, txnType models.TransactionType, description string) (*models.Transaction, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if err := lockUser(tx, userID); err != nil {
+		return nil, err
+	}
+
+	txn := &models.Transaction{
+		UserID:      userID,
+		Type:        txnType,
+		Amount:      amount,
+		Description: description,
+	}
+	err := Post(tx, txn, []Posting{
+		{Account: ExternalAccount, Amount: -amount},
+		{Account: WalletAccount(userID), Amount: amount},
+	})
+	return txn, err
+}
+
+// Debit moves money from a user's wallet out of the platform
+func Debit(tx *gorm.DB, userID string, amount float64, txnType models.TransactionType, description string) (*models.Transaction, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if err := lockUser(tx, userID); err != nil {
+		return nil, err
+	}
+
+	available, err := Balance(tx, WalletAccount(userID))
+	if err != nil {
+		return nil, err
+	}
+	if available < Round(amount) {
+		return nil, ErrInsufficientFunds
+	}
+
+	txn := &models.Transaction{
+		UserID:      userID,
+		Type:        txnType,
+		Amount:      amount,
+		Description: description,
+	}
+	err = Post(tx, txn, []Posting{
+		{Account: WalletAccount(userID), Amount: -amount},
+		{Account: ExternalAccount, Amount: amount},
+	})
+	return txn, err
+}
+
+// Mismatch describes a user whose cached wallet balance disagrees with the
+// ledger
+type Mismatch struct {
+	UserID        string  `json:"user_id"`
+	CachedBalance float64 `json:"cached_balance"`
+	LedgerBalance float64 `json:"ledger_balance"`
+}
+
+// Reconcile compares every user's cached wallet_balance with their ledger
+// balance and returns the users that differ
+func Reconcile(db *gorm.DB) ([]Mismatch, error) {
+	var rows []Mismatch
+	err := db.Raw(`
+		SELECT u.id AS user_id,
+		       COALESCE(u.wallet_balance, 0) AS cached_balance,
+		       COALESCE(l.balance, 0) AS ledger_balance
+		FROM users u
+		LEFT JOIN (
+			SELECT account, SUM(amount) AS balance
+			FROM ledger_entries
+			WHERE account LIKE 'wallet:%'
+			GROUP BY account
+		) l ON l.account = 'wallet:' || u.id
+		WHERE ROUND(COALESCE(u.wallet_balance, 0)::numeric, 2) <> ROUND(COALESCE(l.balance, 0)::numeric, 2)
+		ORDER BY u.id`).Scan(&rows).Error
+	return rows, err
+}
+
+// Round rounds an amount to two decimal places
+func Round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// lockUser takes a row lock on the user so concurrent postings against the
+// same wallet are serialized. Deleted users are included: their bids still
+// have to be captured, released or refunded.
+func lockUser(tx *gorm.DB, userID string) error {
+	var user models.User
+	return tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&user, "id = ?", userID).Error
+}
+
+// refreshBalance recomputes the cached wallet_balance column from the
+// ledger, for deleted users too
+func refreshBalance(tx *gorm.DB, userID string) error {
+	return tx.Unscoped().Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("wallet_balance", gorm.Expr(
+			"(SELECT COALESCE(SUM(amount), 0) FROM ledger_entries WHERE account = ?)",
+			WalletAccount(userID),
+		)).Error
+}
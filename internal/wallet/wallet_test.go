@@ -0,0 +1,232 @@
+package wallet
+
+import (
+	"context"
+	"spotlight-backend-go/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ledger applies postings in memory the way Post writes them to the
+// database
+type ledger map[string]float64
+
+func (l ledger) post(t *testing.T, postings []Posting) {
+	t.Helper()
+	if err := checkBalanced(postings); err != nil {
+		t.Fatalf("postings %v: %v", postings, err)
+	}
+	for _, p := range postings {
+		l[p.Account] = Round(l[p.Account] + p.Amount)
+	}
+}
+
+func (l ledger) total() float64 {
+	var sum float64
+	for _, amount := range l {
+		sum += amount
+	}
+	return Round(sum)
+}
+
+func TestCheckBalanced(t *testing.T) {
+	tests := []struct {
+		name     string
+		postings []Posting
+		wantErr  bool
+	}{
+		{name: "empty"},
+		{
+			name: "balanced",
+			postings: []Posting{
+				{Account: ExternalAccount, Amount: -25},
+				{Account: WalletAccount("u1"), Amount: 25},
+			},
+		},
+		{
+			name: "balanced after rounding",
+			postings: []Posting{
+				{Account: WalletAccount("u1"), Amount: -10.004},
+				{Account: WalletAccount("u2"), Amount: 10.001},
+			},
+		},
+		{
+			name: "unbalanced",
+			postings: []Posting{
+				{Account: WalletAccount("u1"), Amount: -10},
+				{Account: WalletAccount("u2"), Amount: 9.99},
+			},
+			wantErr: true,
+		},
+		{
+			name:     "one sided",
+			postings: []Posting{{Account: WalletAccount("u1"), Amount: 5}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkBalanced(tt.postings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkBalanced() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckBalancedRoundsAmounts(t *testing.T) {
+	postings := []Posting{
+		{Account: WalletAccount("u1"), Amount: -10.004},
+		{Account: WalletAccount("u2"), Amount: 10.004},
+	}
+	if err := checkBalanced(postings); err != nil {
+		t.Fatal(err)
+	}
+	if postings[0].Amount != -10 || postings[1].Amount != 10 {
+		t.Fatalf("amounts not rounded to cents: %v", postings)
+	}
+}
+
+func TestPostRejectsUnbalancedPostings(t *testing.T) {
+	// The check happens before the database is touched
+	err := Post(nil, &models.Transaction{UserID: "u1"}, []Posting{
+		{Account: WalletAccount("u1"), Amount: 10},
+	})
+	if err == nil {
+		t.Fatal("Post() accepted unbalanced postings")
+	}
+}
+
+func TestEscrowHoldAndRelease(t *testing.T) {
+	l := ledger{}
+	l.post(t, []Posting{
+		{Account: ExternalAccount, Amount: -100},
+		{Account: WalletAccount("fan"), Amount: 100},
+	})
+
+	// Place a bid, raise it, then lower it; only the difference moves
+	l.post(t, holdPostings("fan", "e1", 40))
+	l.post(t, holdPostings("fan", "e1", 60-40))
+	l.post(t, holdPostings("fan", "e1", 50-60))
+
+	escrow := EscrowAccount("fan", "e1")
+	if l[escrow] != 50 {
+		t.Fatalf("escrow = %v, want 50", l[escrow])
+	}
+	if l[WalletAccount("fan")] != 50 {
+		t.Fatalf("wallet = %v, want 50", l[WalletAccount("fan")])
+	}
+
+	l.post(t, releasePostings("fan", "e1", l[escrow]))
+	if l[escrow] != 0 {
+		t.Fatalf("escrow after release = %v, want 0", l[escrow])
+	}
+	if l[WalletAccount("fan")] != 100 {
+		t.Fatalf("wallet after release = %v, want 100", l[WalletAccount("fan")])
+	}
+	if l.total() != 0 {
+		t.Fatalf("ledger does not balance: %v", l)
+	}
+}
+
+func TestEscrowCapture(t *testing.T) {
+	l := ledger{}
+	l.post(t, []Posting{
+		{Account: ExternalAccount, Amount: -80},
+		{Account: WalletAccount("fan"), Amount: 80},
+	})
+	l.post(t, holdPostings("fan", "e1", 75.5))
+
+	escrow := EscrowAccount("fan", "e1")
+	l.post(t, capturePostings("fan", "host", "e1", l[escrow]))
+
+	if l[escrow] != 0 {
+		t.Fatalf("escrow after capture = %v, want 0", l[escrow])
+	}
+	if l[WalletAccount("host")] != 75.5 {
+		t.Fatalf("host wallet = %v, want 75.5", l[WalletAccount("host")])
+	}
+	if l[WalletAccount("fan")] != 4.5 {
+		t.Fatalf("fan wallet = %v, want 4.5", l[WalletAccount("fan")])
+	}
+	if l.total() != 0 {
+		t.Fatalf("ledger does not balance: %v", l)
+	}
+}
+
+func TestEscrowAccountsAreSeparatePerEvent(t *testing.T) {
+	l := ledger{}
+	l.post(t, []Posting{
+		{Account: ExternalAccount, Amount: -100},
+		{Account: WalletAccount("fan"), Amount: 100},
+	})
+	l.post(t, holdPostings("fan", "e1", 30))
+	l.post(t, holdPostings("fan", "e2", 20))
+
+	l.post(t, releasePostings("fan", "e1", l[EscrowAccount("fan", "e1")]))
+	if l[EscrowAccount("fan", "e2")] != 20 {
+		t.Fatalf("releasing e1 changed e2's escrow to %v", l[EscrowAccount("fan", "e2")])
+	}
+	if l[WalletAccount("fan")] != 80 {
+		t.Fatalf("wallet = %v, want 80", l[WalletAccount("fan")])
+	}
+}
+
+// sqlRecorder is a gorm logger that keeps the SQL of every statement
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+// dryRunDB returns a handle that builds SQL without a database
+func dryRunDB(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=spotlight_test"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 recorder,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, recorder
+}
+
+func TestWalletIncludesSoftDeletedUsers(t *testing.T) {
+	db, recorder := dryRunDB(t)
+
+	// A deleted fan's escrow must still be captured, released or refunded,
+	// so the queries Post runs on users may not skip soft-deleted rows
+	if err := lockUser(db, "deleted-fan"); err != nil {
+		t.Fatalf("lockUser() error = %v", err)
+	}
+	if err := refreshBalance(db, "deleted-fan"); err != nil {
+		t.Fatalf("refreshBalance() error = %v", err)
+	}
+
+	var userStatements int
+	for _, sql := range recorder.statements {
+		if !strings.Contains(sql, `"users"`) {
+			continue
+		}
+		userStatements++
+		if strings.Contains(sql, "deleted_at") {
+			t.Errorf("statement skips soft-deleted users: %s", sql)
+		}
+	}
+	if userStatements == 0 {
+		t.Fatalf("no statements on users recorded: %v", recorder.statements)
+	}
+}
@@ -0,0 +1,466 @@
+-- 0001 initial schema
+-- The schema as it stood when versioned migrations were introduced. Every
+-- statement is idempotent, so it also applies cleanly to databases set up
+-- by the old run-everything-at-startup script.
+
+-- Users table
+CREATE TABLE IF NOT EXISTS users (
+    id CHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    username VARCHAR(255) UNIQUE NOT NULL,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    photo_url TEXT,
+    bio TEXT,
+    role VARCHAR(50) NOT NULL,
+    wallet_balance NUMERIC(12,2) DEFAULT 0,
+    media_gallery JSONB DEFAULT '[]',
+    cover_photo_url TEXT,
+    follower_count INTEGER DEFAULT 0,
+    instagram_handle VARCHAR(255),
+    verified BOOLEAN DEFAULT false,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Events table
+CREATE TABLE IF NOT EXISTS events (
+    id CHAR(36) PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    date TIMESTAMP NOT NULL,
+    location VARCHAR(255),
+    host_id CHAR(36) REFERENCES users(id) ON DELETE SET NULL,
+    category VARCHAR(50),
+    images JSONB DEFAULT '[]',
+    min_bid NUMERIC(12,2) NOT NULL,
+    capacity INTEGER NOT NULL,
+    bid_deadline TIMESTAMP NOT NULL,
+    status VARCHAR(50) DEFAULT 'upcoming',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Event Attendees table
+CREATE TABLE IF NOT EXISTS event_attendees (
+    user_id CHAR(36) REFERENCES users(id) ON DELETE CASCADE,
+    event_id CHAR(36) REFERENCES events(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (user_id, event_id)
+);
+
+-- Applications table
+CREATE TABLE IF NOT EXISTS applications (
+    id SERIAL PRIMARY KEY,
+    event_id CHAR(36) REFERENCES events(id) ON DELETE CASCADE,
+    fan_id CHAR(36) REFERENCES users(id) ON DELETE CASCADE,
+    bid_amount NUMERIC(12,2) NOT NULL,
+    message TEXT,
+    status VARCHAR(50) DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(event_id, fan_id)
+);
+
+-- Chat Rooms table
+CREATE TABLE IF NOT EXISTS chat_rooms (
+    id SERIAL PRIMARY KEY,
+    event_id CHAR(36) REFERENCES events(id) ON DELETE CASCADE,
+    status VARCHAR(50) DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Messages table
+CREATE TABLE IF NOT EXISTS messages (
+    id SERIAL PRIMARY KEY,
+    chat_room_id INTEGER REFERENCES chat_rooms(id) ON DELETE CASCADE,
+    sender_id CHAR(36) REFERENCES users(id) ON DELETE SET NULL,
+    content TEXT NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Add new profile fields to users table if they don't exist
+DO $$ 
+BEGIN
+    -- Add age column if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'age') THEN
+        ALTER TABLE users ADD COLUMN age INTEGER;
+    END IF;
+
+    -- Add gender column if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'gender') THEN
+        ALTER TABLE users ADD COLUMN gender VARCHAR(50);
+    END IF;
+
+    -- Add height column if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'height') THEN
+        ALTER TABLE users ADD COLUMN height INTEGER;
+    END IF;
+
+    -- Add work column if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'work') THEN
+        ALTER TABLE users ADD COLUMN work TEXT;
+    END IF;
+
+    -- Add education column if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'education') THEN
+        ALTER TABLE users ADD COLUMN education TEXT;
+    END IF;
+
+    -- Add education_level column if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'education_level') THEN
+        ALTER TABLE users ADD COLUMN education_level VARCHAR(50);
+    END IF;
+
+    -- Add drinking column if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'drinking') THEN
+        ALTER TABLE users ADD COLUMN drinking VARCHAR(50);
+    END IF;
+
+    -- Add location column if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'location') THEN
+        ALTER TABLE users ADD COLUMN location TEXT;
+    END IF;
+
+    -- Add government_id_url column if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'government_id_url') THEN
+        ALTER TABLE users ADD COLUMN government_id_url TEXT;
+    END IF;
+
+    -- Add verified_at column if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'verified_at') THEN
+        ALTER TABLE users ADD COLUMN verified_at TIMESTAMP;
+    END IF;
+
+    -- Add events_hosted_count column if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'events_hosted_count') THEN
+        ALTER TABLE users ADD COLUMN events_hosted_count INTEGER NOT NULL DEFAULT 0;
+    END IF;
+
+    -- Add mobile_number column if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'mobile_number') THEN
+        ALTER TABLE users ADD COLUMN mobile_number VARCHAR(20);
+    END IF;
+END $$; 
+
+-- OTP Codes table
+CREATE TABLE IF NOT EXISTS otp_codes (
+    id CHAR(36) PRIMARY KEY,
+    mobile_number VARCHAR(20) NOT NULL,
+    code_hash VARCHAR(255) NOT NULL,
+    attempts INTEGER DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    consumed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_otp_codes_mobile_number ON otp_codes(mobile_number);
+
+-- Sessions table
+CREATE TABLE IF NOT EXISTS sessions (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    refresh_token_hash VARCHAR(64) NOT NULL,
+    device_name VARCHAR(255),
+    user_agent TEXT,
+    ip_address VARCHAR(64),
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP DEFAULT NOW(),
+    revoked_at TIMESTAMP,
+    revoked_reason VARCHAR(50),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+
+-- Add moderation fields to users table if they don't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'suspended_at') THEN
+        ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'suspended_reason') THEN
+        ALTER TABLE users ADD COLUMN suspended_reason TEXT;
+    END IF;
+END $$;
+
+-- Verification Requests table
+CREATE TABLE IF NOT EXISTS verification_requests (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    document_type VARCHAR(50),
+    document_url TEXT NOT NULL,
+    status VARCHAR(50) DEFAULT 'submitted',
+    rejection_reason TEXT,
+    reviewer_id CHAR(36) REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_requests_user_id ON verification_requests(user_id);
+CREATE INDEX IF NOT EXISTS idx_verification_requests_status ON verification_requests(status);
+
+-- Notifications table
+CREATE TABLE IF NOT EXISTS notifications (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255),
+    message TEXT,
+    read BOOLEAN DEFAULT false,
+    related_event_id CHAR(36),
+    related_user_id CHAR(36),
+    related_bid_id CHAR(36),
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id);
+
+-- Transactions table
+CREATE TABLE IF NOT EXISTS transactions (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    amount NUMERIC(12,2) NOT NULL,
+    balance_after NUMERIC(12,2),
+    description TEXT,
+    related_event_id TEXT,
+    related_user_id TEXT,
+    related_bid_id TEXT,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions(user_id);
+
+-- Ledger Entries table
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id CHAR(36) PRIMARY KEY,
+    transaction_id CHAR(36) NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    account VARCHAR(255) NOT NULL,
+    amount NUMERIC(12,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_transaction_id ON ledger_entries(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account ON ledger_entries(account);
+
+-- Payment Orders table
+CREATE TABLE IF NOT EXISTS payment_orders (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50),
+    provider_order_id VARCHAR(255) UNIQUE NOT NULL,
+    provider_payment_id VARCHAR(255) UNIQUE,
+    amount NUMERIC(12,2) NOT NULL,
+    status VARCHAR(50) DEFAULT 'created',
+    transaction_id CHAR(36),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_orders_user_id ON payment_orders(user_id);
+
+-- Withdrawals table
+CREATE TABLE IF NOT EXISTS withdrawals (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount NUMERIC(12,2) NOT NULL,
+    destination TEXT,
+    provider VARCHAR(50),
+    provider_payout_id VARCHAR(255) UNIQUE,
+    status VARCHAR(50) DEFAULT 'requested',
+    failure_reason TEXT,
+    transaction_id CHAR(36),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_withdrawals_user_id ON withdrawals(user_id);
+CREATE INDEX IF NOT EXISTS idx_withdrawals_status ON withdrawals(status);
+
+-- Chat rooms are either an event's group chat or a direct chat between two users
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'chat_rooms' AND column_name = 'type') THEN
+        ALTER TABLE chat_rooms ADD COLUMN type VARCHAR(20) NOT NULL DEFAULT 'event';
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'chat_rooms' AND column_name = 'direct_key') THEN
+        ALTER TABLE chat_rooms ADD COLUMN direct_key VARCHAR(73);
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_chat_rooms_event_id ON chat_rooms(event_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_chat_rooms_direct_key ON chat_rooms(direct_key);
+CREATE INDEX IF NOT EXISTS idx_messages_room_id ON messages(chat_room_id, id);
+
+-- Chat Room Members table
+CREATE TABLE IF NOT EXISTS chat_room_members (
+    chat_room_id INTEGER NOT NULL REFERENCES chat_rooms(id) ON DELETE CASCADE,
+    user_id CHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_read_message_id INTEGER NOT NULL DEFAULT 0,
+    last_read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (chat_room_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_room_members_user_id ON chat_room_members(user_id);
+
+-- Indexes for event listing filters, sorts and counts
+CREATE INDEX IF NOT EXISTS idx_events_date_id ON events(date, id);
+CREATE INDEX IF NOT EXISTS idx_events_min_bid_id ON events(min_bid, id);
+CREATE INDEX IF NOT EXISTS idx_events_category ON events(category);
+CREATE INDEX IF NOT EXISTS idx_events_status ON events(status);
+CREATE INDEX IF NOT EXISTS idx_events_host_id ON events(host_id);
+CREATE INDEX IF NOT EXISTS idx_applications_event_id ON applications(event_id);
+CREATE INDEX IF NOT EXISTS idx_event_attendees_event_id ON event_attendees(event_id);
+
+-- Full-text search columns. The 'simple' configuration keeps names and
+-- places unstemmed so prefix queries match what users type.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'events' AND column_name = 'search_vector') THEN
+        ALTER TABLE events ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+            setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+            setweight(to_tsvector('simple', coalesce(category, '')), 'B') ||
+            setweight(to_tsvector('simple', coalesce(location, '')), 'B') ||
+            setweight(to_tsvector('simple', coalesce(description, '')), 'C')
+        ) STORED;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'search_vector') THEN
+        ALTER TABLE users ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+            setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+            setweight(to_tsvector('simple', coalesce(username, '')), 'A') ||
+            setweight(to_tsvector('simple', coalesce(instagram_handle, '')), 'A') ||
+            setweight(to_tsvector('simple', coalesce(work, '')), 'B') ||
+            setweight(to_tsvector('simple', coalesce(bio, '')), 'C')
+        ) STORED;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_events_search_vector ON events USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_users_search_vector ON users USING GIN (search_vector);
+
+-- Event lifecycle: the scheduler records when bidding closed
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'events' AND column_name = 'bidding_closed_at') THEN
+        ALTER TABLE events ADD COLUMN bidding_closed_at TIMESTAMP;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_events_status_bid_deadline ON events(status, bid_deadline);
+
+-- Auction modes: how bids compete, the live or final top bid and winner
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'events' AND column_name = 'auction_mode') THEN
+        ALTER TABLE events ADD COLUMN auction_mode VARCHAR(16) NOT NULL DEFAULT 'sealed';
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'events' AND column_name = 'min_increment') THEN
+        ALTER TABLE events ADD COLUMN min_increment NUMERIC(12,2) NOT NULL DEFAULT 0;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'events' AND column_name = 'current_bid') THEN
+        ALTER TABLE events ADD COLUMN current_bid NUMERIC(12,2) NOT NULL DEFAULT 0;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'events' AND column_name = 'winner_id') THEN
+        ALTER TABLE events ADD COLUMN winner_id CHAR(36) REFERENCES users(id) ON DELETE SET NULL;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'applications' AND column_name = 'bid_at') THEN
+        ALTER TABLE applications ADD COLUMN bid_at TIMESTAMP;
+        UPDATE applications SET bid_at = created_at;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_applications_event_status_bid ON applications(event_id, status, bid_amount DESC, bid_at);
+
+-- Bids table: every revision of a fan's bid, at most one active per event
+CREATE TABLE IF NOT EXISTS bids (
+    id CHAR(36) PRIMARY KEY,
+    event_id CHAR(36) NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    user_id CHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    application_id CHAR(36),
+    amount NUMERIC(12,2) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'bids' AND column_name = 'application_id') THEN
+        ALTER TABLE bids ADD COLUMN application_id CHAR(36);
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'bids' AND column_name = 'status') THEN
+        ALTER TABLE bids ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active';
+    END IF;
+
+    -- Older rows were never revised in place: keep only each fan's latest
+    -- bid active and link bids to their application
+    UPDATE bids b SET status = 'superseded'
+    WHERE b.status = 'active' AND EXISTS (
+        SELECT 1 FROM bids n
+        WHERE n.event_id = b.event_id AND n.user_id = b.user_id AND n.status = 'active'
+          AND (n.created_at, n.id) > (b.created_at, b.id)
+    );
+    UPDATE bids b SET application_id = a.id
+    FROM applications a
+    WHERE b.application_id IS NULL AND a.event_id = b.event_id AND a.fan_id = b.user_id;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bids_one_active ON bids(event_id, user_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_bids_event_user ON bids(event_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_bids_application_id ON bids(application_id);
+
+-- Host dashboard: daily bid series and per-event earnings
+CREATE INDEX IF NOT EXISTS idx_bids_event_created_at ON bids(event_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_related_event_id ON transactions(related_event_id);
+
+-- Follows table: who follows whom; users.follower_count is derived from it
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id CHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    following_id CHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (follower_id, following_id),
+    CHECK (follower_id <> following_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_follows_following_id ON follows(following_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_follows_follower_id ON follows(follower_id, created_at DESC);
+
+-- follower_count used to be self-reported at registration; recompute it
+UPDATE users u SET follower_count = COALESCE(f.count, 0)
+FROM users x
+LEFT JOIN (SELECT following_id, COUNT(*) AS count FROM follows GROUP BY following_id) f ON f.following_id = x.id
+WHERE x.id = u.id AND u.follower_count IS DISTINCT FROM COALESCE(f.count, 0);
+
+-- Identity documents live in the private store; document_key locates them
+-- and document_deleted_at records their deletion after the decision
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'verification_requests' AND column_name = 'document_key') THEN
+        ALTER TABLE verification_requests ADD COLUMN document_key TEXT;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'verification_requests' AND column_name = 'document_deleted_at') THEN
+        ALTER TABLE verification_requests ADD COLUMN document_deleted_at TIMESTAMP;
+    END IF;
+END $$;
+
+-- Stop exposing document URLs of users without an open verification request
+UPDATE users u SET government_id_url = ''
+WHERE COALESCE(u.government_id_url, '') <> '' AND NOT EXISTS (
+    SELECT 1 FROM verification_requests v
+    WHERE v.user_id = u.id AND v.status IN ('submitted', 'under_review')
+);
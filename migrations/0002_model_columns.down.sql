@@ -0,0 +1,8 @@
+-- 0002 model columns
+-- Application IDs stay text: UUIDs cannot be turned back into serials
+ALTER TABLE users ALTER COLUMN height TYPE INTEGER USING ROUND(height)::INTEGER;
+
+ALTER TABLE users DROP COLUMN IF EXISTS is_verified;
+ALTER TABLE users DROP COLUMN IF EXISTS interests;
+ALTER TABLE users DROP COLUMN IF EXISTS profile_photos;
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_url;
@@ -0,0 +1,25 @@
+-- 0002 model columns
+-- Columns that only GORM AutoMigrate used to create. With AutoMigrate gone
+-- from startup, the migrations are the only source of the schema.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_url TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS profile_photos JSONB DEFAULT '[]';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS interests JSONB DEFAULT '[]';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_verified BOOLEAN DEFAULT false;
+
+-- photo_url predates avatar_url
+UPDATE users SET avatar_url = photo_url
+WHERE COALESCE(avatar_url, '') = '' AND COALESCE(photo_url, '') <> '';
+
+-- Heights are stored with decimals
+ALTER TABLE users ALTER COLUMN height TYPE NUMERIC USING height::NUMERIC;
+
+-- Application IDs are UUIDs, not serials
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'applications' AND column_name = 'id' AND data_type = 'integer') THEN
+        ALTER TABLE applications ALTER COLUMN id DROP DEFAULT;
+        ALTER TABLE applications ALTER COLUMN id TYPE TEXT USING id::TEXT;
+        DROP SEQUENCE IF EXISTS applications_id_seq;
+    END IF;
+END $$;
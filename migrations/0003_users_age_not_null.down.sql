@@ -0,0 +1,3 @@
+-- 0003 users age not null
+ALTER TABLE users ALTER COLUMN age DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN age DROP DEFAULT;
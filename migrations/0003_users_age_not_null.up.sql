@@ -0,0 +1,5 @@
+-- 0003 users age not null
+-- The model has always required an age; rows created before it did get 0
+UPDATE users SET age = 0 WHERE age IS NULL;
+ALTER TABLE users ALTER COLUMN age SET DEFAULT 0;
+ALTER TABLE users ALTER COLUMN age SET NOT NULL;
@@ -0,0 +1,5 @@
+-- 0005 wallet opening balances
+-- users.wallet_balance was left untouched, so removing the postings is enough
+DELETE FROM ledger_entries
+WHERE transaction_id IN (SELECT id FROM transactions WHERE type = 'opening_balance');
+DELETE FROM transactions WHERE type = 'opening_balance';
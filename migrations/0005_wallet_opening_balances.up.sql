@@ -0,0 +1,29 @@
+-- 0005 wallet opening balances
+-- Before the ledger, users.wallet_balance was the only record of a wallet.
+-- Every posting recomputes that column from the ledger, so any balance the
+-- ledger does not account for yet is posted as an opening balance from the
+-- external account first.
+CREATE TEMP TABLE opening_balances ON COMMIT DROP AS
+SELECT u.id AS user_id,
+       gen_random_uuid()::text AS transaction_id,
+       ROUND(COALESCE(u.wallet_balance, 0)::numeric, 2) - COALESCE(l.balance, 0) AS amount,
+       ROUND(COALESCE(u.wallet_balance, 0)::numeric, 2) AS balance_after
+FROM users u
+LEFT JOIN (
+    SELECT account, SUM(amount) AS balance
+    FROM ledger_entries
+    WHERE account LIKE 'wallet:%'
+    GROUP BY account
+) l ON l.account = 'wallet:' || u.id
+WHERE ROUND(COALESCE(u.wallet_balance, 0)::numeric, 2) <> COALESCE(l.balance, 0);
+
+INSERT INTO transactions (id, user_id, type, amount, balance_after, description, created_at)
+SELECT transaction_id, user_id, 'opening_balance', amount, balance_after, 'Opening balance', NOW()
+FROM opening_balances;
+
+INSERT INTO ledger_entries (id, transaction_id, account, amount, created_at)
+SELECT gen_random_uuid()::text, transaction_id, 'external', -amount, NOW()
+FROM opening_balances
+UNION ALL
+SELECT gen_random_uuid()::text, transaction_id, 'wallet:' || user_id, amount, NOW()
+FROM opening_balances;
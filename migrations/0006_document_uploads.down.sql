@@ -0,0 +1,2 @@
+-- 0006 document uploads
+DROP TABLE IF EXISTS document_uploads;
@@ -0,0 +1,11 @@
+-- 0006 document uploads
+-- Identity documents uploaded to the private store but not submitted yet,
+-- so abandoned uploads can be found and deleted
+CREATE TABLE IF NOT EXISTS document_uploads (
+    key TEXT PRIMARY KEY,
+    user_id CHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_uploads_user_id ON document_uploads(user_id);
+CREATE INDEX IF NOT EXISTS idx_document_uploads_created_at ON document_uploads(created_at);
@@ -6,6 +6,10 @@ $env:DB_PASSWORD = "postgresspotlight@mma"
 $env:DB_NAME = "spotlight"
 $env:LOCAL_DEV = "true"
 $env:JWT_SECRET = "spotlight_jwt_secret_key_2024_secure"
+$env:PAYMENT_PROVIDER = "fake"
+$env:PAYMENT_WEBHOOK_SECRET = "spotlight_local_webhook_secret"
+$env:SMS_PROVIDER = "log"
+$env:STORAGE_SIGNING_SECRET = "spotlight_local_storage_signing_secret"
 $env:PGPASSWORD = $env:DB_PASSWORD
 $env:GOOGLE_APPLICATION_CREDENTIALS = "$PSScriptRoot\config\client_secret_1089184396463-55fkfc50skejp2cqe448ctrgff40oonj.apps.googleusercontent.com.json"
 $env:GOOGLE_CLIENT_ID = "1089184396463-55fkfc50skejp2cqe448ctrgff40oonj.apps.googleusercontent.com"
@@ -39,9 +43,10 @@ Write-Host "Database reset successful!"
 
 # Run migrations
 Write-Host "Running migrations..."
-Get-ChildItem -Path "migrations\*.sql" | ForEach-Object {
-    Write-Host "Running migration: $_"
-    & $PSQL_PATH -h $env:DB_HOST -p $env:DB_PORT -U $env:DB_USER -d $env:DB_NAME -f $_.FullName
+go run ./cmd/migrate up
+if ($LASTEXITCODE -ne 0) {
+    Write-Error "Error: Migrations failed"
+    exit 1
 }
 
 # Run the seed file
@@ -11,6 +11,10 @@ export DB_PASSWORD="postgresspotlight@mma"
 export DB_NAME="spotlight"
 export LOCAL_DEV="true"
 export JWT_SECRET="spotlight_jwt_secret_key_2024_secure"
+export PAYMENT_PROVIDER="fake"
+export PAYMENT_WEBHOOK_SECRET="spotlight_local_webhook_secret"
+export SMS_PROVIDER="log"
+export STORAGE_SIGNING_SECRET="spotlight_local_storage_signing_secret"
 export PGPASSWORD=$DB_PASSWORD
 export GOOGLE_APPLICATION_CREDENTIALS="$(dirname "$0")/config/client_secret_1089184396463-55fkfc50skejp2cqe448ctrgff40oonj.apps.googleusercontent.com.json"
 export GOOGLE_CLIENT_ID="1089184396463-55fkfc50skejp2cqe448ctrgff40oonj.apps.googleusercontent.com"
@@ -48,13 +52,8 @@ reset_database() {
 run_migrations() {
     echo "Running migrations..."
     
-    # Run all migrations in order
-    for migration in migrations/*.sql; do
-        if [ -f "$migration" ]; then
-            echo "Running migration: $migration"
-            psql -h $DB_HOST -p $DB_PORT -U $DB_USER -d $DB_NAME -f "$migration"
-        fi
-    done
+    # Apply pending migrations
+    go run ./cmd/migrate up
 }
 
 # Function to start the server
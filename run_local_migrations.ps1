@@ -5,12 +5,12 @@ $env:DB_USER = "postgres"
 $env:DB_PASSWORD = "postgresspotlight@mma"
 $env:DB_NAME = "spotlight"
 
-# Run all migrations in sequence
+# Apply pending migrations
 Write-Host "Running migrations..."
-Get-ChildItem -Path "migrations" -Filter "*.sql" | Sort-Object Name | ForEach-Object {
-    Write-Host "Running migration: $($_.Name)"
-    $env:PGPASSWORD = $env:DB_PASSWORD
-    psql -h $env:DB_HOST -p $env:DB_PORT -U $env:DB_USER -d $env:DB_NAME -f $_.FullName
+go run ./cmd/migrate up
+if ($LASTEXITCODE -ne 0) {
+    Write-Error "Error: Migrations failed"
+    exit 1
 }
 
 Write-Host "Migrations completed successfully!" 
\ No newline at end of file
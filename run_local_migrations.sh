@@ -10,13 +10,8 @@ export DB_USER=postgres
 export DB_PASSWORD=postgresspotlight@mma
 export DB_NAME=spotlight
 
-# Run all migrations in sequence
+# Apply pending migrations
 echo "Running migrations..."
-for migration in migrations/*.sql; do
-  if [ -f "$migration" ]; then
-    echo "Running migration: $migration"
-    PGPASSWORD=$DB_PASSWORD psql -h localhost -p $DB_PORT -U $DB_USER -d $DB_NAME -f "$migration"
-  fi
-done
+go run ./cmd/migrate up
 
 echo "Migrations completed successfully!" 
\ No newline at end of file